@@ -9,11 +9,19 @@ import (
 	"strings"
 
 	"github.com/mickep76/compress"
+	_ "github.com/mickep76/compress/bgzf"
+	_ "github.com/mickep76/compress/brotli"
+	_ "github.com/mickep76/compress/bzip2"
+	_ "github.com/mickep76/compress/flate"
 	_ "github.com/mickep76/compress/gzip"
+	_ "github.com/mickep76/compress/lz4"
 	_ "github.com/mickep76/compress/lzw"
+	_ "github.com/mickep76/compress/s2"
 	_ "github.com/mickep76/compress/snappy"
+	_ "github.com/mickep76/compress/store"
 	_ "github.com/mickep76/compress/xz"
 	_ "github.com/mickep76/compress/zlib"
+	_ "github.com/mickep76/compress/zstd"
 )
 
 func usage() {
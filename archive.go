@@ -0,0 +1,83 @@
+package compress
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// EncodeArchive algorithm bundling the files in a tar archive, in name order, and encoding it i.e. a tar.gz.
+func EncodeArchive(a Algorithm, files map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		h := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(files[name])),
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return Encode(a, buf.Bytes())
+}
+
+// DecodeArchive algorithm decoding a tar archive and returning the regular files by name.
+// An archive with the same name twice returns ErrDuplicateName.
+func DecodeArchive(a Algorithm, v []byte) (map[string][]byte, error) {
+	d, err := a.NewDecoder(bytes.NewReader(v))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(d)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			_ = d.Close()
+			return nil, err
+		}
+
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if _, ok := files[h.Name]; ok {
+			_ = d.Close()
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateName, h.Name)
+		}
+
+		if files[h.Name], err = ioutil.ReadAll(tr); err != nil {
+			_ = d.Close()
+			return nil, err
+		}
+	}
+
+	if err := d.Close(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
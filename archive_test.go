@@ -0,0 +1,59 @@
+package compress_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeDecodeArchive(t *testing.T) {
+	exp := map[string][]byte{
+		"abc.txt":     []byte("abc123\n"),
+		"def.txt":     []byte("def456\n"),
+		"dir/ghi.txt": bytes.Repeat([]byte("ghi789\n"), 1000),
+	}
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := compress.EncodeArchive(a, exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := compress.DecodeArchive(a, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected %d files got %d files that don't match", len(exp), len(got))
+	}
+
+	// Tar allows the same name twice.
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for i := 0; i < 2; i++ {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "abc.txt", Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if encoded, err = a.Encode(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := compress.DecodeArchive(a, encoded); !errors.Is(err, compress.ErrDuplicateName) {
+		t.Errorf("expected %v got %v", compress.ErrDuplicateName, err)
+	}
+}
@@ -0,0 +1,69 @@
+package compress_test
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// BenchmarkAlgorithms encode and decode with every registered algorithm on a text and a random corpus.
+// testdata/corpus.txt is the LICENSE followed by the README.
+func BenchmarkAlgorithms(b *testing.B) {
+	text, err := ioutil.ReadFile("testdata/corpus.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	random := make([]byte, 64*1024)
+	_, _ = rand.New(rand.NewSource(1)).Read(random)
+
+	corpora := []struct {
+		name string
+		data []byte
+	}{
+		{"text", text},
+		{"random", random},
+	}
+
+	for _, name := range algorithms() {
+		a, err := compress.NewAlgorithm(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, c := range corpora {
+			encoded, err := a.Encode(c.data)
+			if errors.Cause(err) == compress.ErrEncodeUnsupported {
+				// Decode only, i.e. bzip2.
+				continue
+			} else if err != nil {
+				b.Fatalf("%s: %v", name, err)
+			}
+
+			b.Run(name+"/"+c.name+"/encode", func(b *testing.B) {
+				b.SetBytes(int64(len(c.data)))
+				b.ReportAllocs()
+				b.ReportMetric(float64(len(encoded)), "encoded-bytes")
+				for i := 0; i < b.N; i++ {
+					if _, err := a.Encode(c.data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run(name+"/"+c.name+"/decode", func(b *testing.B) {
+				b.SetBytes(int64(len(c.data)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := a.Decode(encoded); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
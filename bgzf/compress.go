@@ -0,0 +1,394 @@
+package bgzf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+const (
+	// maxBlockSize of the uncompressed data in a block, same as samtools so the compressed block fits in 64KB.
+	maxBlockSize = 0xff00
+
+	// maxEncodedSize of a block, BSIZE is stored as a uint16 of the size minus one.
+	maxEncodedSize = 1 << 16
+)
+
+// eofMarker empty block ending a BGZF file.
+var eofMarker = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00, 0x42, 0x43,
+	0x02, 0x00, 0x1b, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.HuffmanOnly,
+	compress.DefaultCompression,
+	compress.NoCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
+type bgzfAlgorithm struct {
+	compress.Options
+
+	level     compress.Level
+	blockSize int
+}
+
+type bgzfEncoder struct {
+	writer io.Writer
+	gzip   *gzip.Writer
+	block  bytes.Buffer
+	buf    []byte
+
+	// at blocks are written directly and BSIZE patched afterwards, instead of buffering the block.
+	at *atWriter
+}
+
+// atWriter writes sequentially to an io.WriterAt.
+type atWriter struct {
+	writer io.WriterAt
+	offset int64
+}
+
+func (w *atWriter) Write(v []byte) (int, error) {
+	n, err := w.writer.WriteAt(v, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+type bgzfDecoder struct {
+	reader *gzip.Reader
+}
+
+func (a *bgzfAlgorithm) NewAlgorithm() compress.Algorithm {
+	return &bgzfAlgorithm{level: compress.DefaultCompression, blockSize: maxBlockSize}
+}
+
+func (a *bgzfAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *bgzfAlgorithm) Name() string {
+	return "bgzf"
+}
+
+func (a *bgzfAlgorithm) Ext() string {
+	return "bgz"
+}
+
+func (a *bgzfAlgorithm) String() string {
+	return fmt.Sprintf("bgzf(level=%d, block=%d)", a.level, a.blockSize)
+}
+
+func (a *bgzfAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapFlush | compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *bgzfAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+func (a *bgzfAlgorithm) DefaultLevel() compress.Level {
+	return compress.DefaultCompression
+}
+
+func (a *bgzfAlgorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm bgzf level %d", level)
+	}
+	a.level = level
+	return nil
+}
+
+func (a *bgzfAlgorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm bgzf")
+}
+
+// SetName unsupported, samtools expects the header to only have the BC extra field.
+func (a *bgzfAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bgzf")
+}
+
+// SetOS unsupported, see SetName.
+func (a *bgzfAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm bgzf")
+}
+
+// SetExtra unsupported, the extra field holds the block size.
+func (a *bgzfAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm bgzf")
+}
+
+// SetDeterministic is a no-op, the output of bgzf is always the same for the same input.
+func (a *bgzfAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *bgzfAlgorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+func (a *bgzfAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return errors.Wrap(compress.ErrChecksumToggleUnsupported, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm bgzf")
+}
+
+func (a *bgzfAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm bgzf")
+}
+
+// SetBlockSize uncompressed size of a block, at most 65280 bytes.
+func (a *bgzfAlgorithm) SetBlockSize(n int) error {
+	if n <= 0 || n > maxBlockSize {
+		return errors.Wrapf(compress.ErrInvalidBlockSize, "algorithm bgzf block size %d must be between 1 and %d", n, maxBlockSize)
+	}
+	a.blockSize = n
+	return nil
+}
+
+// SetMaxMemory is a no-op, blocks are at most 64KB.
+func (a *bgzfAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm bgzf max memory %d", n)
+	}
+	return nil
+}
+
+func (a *bgzfAlgorithm) Validate() error {
+	return nil
+}
+
+func (a *bgzfAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	e := &bgzfEncoder{writer: w, buf: make([]byte, 0, a.blockSize)}
+	var err error
+	if e.gzip, err = gzip.NewWriterLevel(&e.block, int(a.level)); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// NewEncoderAt encoder writing each block directly to w and patching BSIZE in the header afterwards.
+func (a *bgzfAlgorithm) NewEncoderAt(w io.WriterAt) (compress.Encoder, error) {
+	at := &atWriter{writer: w}
+	e, err := a.NewEncoder(at)
+	if err != nil {
+		return nil, err
+	}
+	e.(*bgzfEncoder).at = at
+	return e, nil
+}
+
+func (a *bgzfAlgorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+// writeBlock compress the buffered data as a gzip member with BSIZE in the BC extra field.
+func (e *bgzfEncoder) writeBlock() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+
+	if e.at != nil {
+		return e.writeBlockAt()
+	}
+
+	e.block.Reset()
+	e.gzip.Reset(&e.block)
+	e.gzip.Extra = []byte{'B', 'C', 2, 0, 0, 0}
+	if _, err := e.gzip.Write(e.buf); err != nil {
+		return err
+	}
+	if err := e.gzip.Close(); err != nil {
+		return err
+	}
+
+	b := e.block.Bytes()
+	if len(b) > maxEncodedSize {
+		return errors.Errorf("algorithm bgzf encoded block size %d exceeds %d", len(b), maxEncodedSize)
+	}
+	binary.LittleEndian.PutUint16(b[16:], uint16(len(b)-1))
+
+	e.buf = e.buf[:0]
+	_, err := e.writer.Write(b)
+	return err
+}
+
+// writeBlockAt compress the buffered data directly to the writer and patch BSIZE at the start of the block.
+func (e *bgzfEncoder) writeBlockAt() error {
+	start := e.at.offset
+	e.gzip.Reset(e.at)
+	e.gzip.Extra = []byte{'B', 'C', 2, 0, 0, 0}
+	if _, err := e.gzip.Write(e.buf); err != nil {
+		return err
+	}
+	if err := e.gzip.Close(); err != nil {
+		return err
+	}
+
+	size := e.at.offset - start
+	if size > maxEncodedSize {
+		return errors.Errorf("algorithm bgzf encoded block size %d exceeds %d", size, maxEncodedSize)
+	}
+	bsize := make([]byte, 2)
+	binary.LittleEndian.PutUint16(bsize, uint16(size-1))
+
+	e.buf = e.buf[:0]
+	_, err := e.at.writer.WriteAt(bsize, start+16)
+	return err
+}
+
+func (e *bgzfEncoder) Write(v []byte) (int, error) {
+	n := 0
+	for len(v) > 0 {
+		c := copy(e.buf[len(e.buf):cap(e.buf)], v)
+		e.buf = e.buf[:len(e.buf)+c]
+		n += c
+		v = v[c:]
+
+		if len(e.buf) == cap(e.buf) {
+			if err := e.writeBlock(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (e *bgzfEncoder) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	for {
+		c, err := r.Read(e.buf[len(e.buf):cap(e.buf)])
+		e.buf = e.buf[:len(e.buf)+c]
+		n += int64(c)
+
+		if len(e.buf) == cap(e.buf) {
+			if err := e.writeBlock(); err != nil {
+				return n, err
+			}
+		}
+
+		if err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+	}
+}
+
+func (e *bgzfEncoder) Reset(w io.Writer) error {
+	e.writer = w
+	e.at = nil
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// Flush ends the current block.
+func (e *bgzfEncoder) Flush() error {
+	return e.writeBlock()
+}
+
+// WriteRecord write v as a single block without the EOF marker, so EncodeRecords can write each record
+// as a member. Records larger than the block size return ErrInvalidBlockSize.
+func (e *bgzfEncoder) WriteRecord(v []byte) error {
+	if len(v) > cap(e.buf) {
+		return errors.Wrapf(compress.ErrInvalidBlockSize, "algorithm bgzf record size %d exceeds block size %d", len(v), cap(e.buf))
+	}
+	if err := e.writeBlock(); err != nil {
+		return err
+	}
+
+	// An empty block is the same as the EOF marker, writeBlock skips it.
+	if len(v) == 0 {
+		_, err := e.writer.Write(eofMarker)
+		return err
+	}
+	e.buf = append(e.buf, v...)
+	return e.writeBlock()
+}
+
+func (e *bgzfEncoder) Close() error {
+	if err := e.writeBlock(); err != nil {
+		return err
+	}
+	_, err := e.writer.Write(eofMarker)
+	return err
+}
+
+func (a *bgzfAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	d := &bgzfDecoder{}
+	var err error
+	if d.reader, err = gzip.NewReader(r); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (a *bgzfAlgorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *bgzfDecoder) Read(v []byte) (int, error) {
+	return d.reader.Read(v)
+}
+
+func (d *bgzfDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+// Multistream false stops at the end of each block.
+func (d *bgzfDecoder) Multistream(ok bool) {
+	d.reader.Multistream(ok)
+}
+
+func (d *bgzfDecoder) Reset(r io.Reader) error {
+	if err := d.reader.Reset(r); err != nil {
+		return err
+	}
+	d.reader.Multistream(true)
+	return nil
+}
+
+func (d *bgzfDecoder) Close() error {
+	return d.reader.Close()
+}
+
+func init() {
+	compress.Register("bgzf", &bgzfAlgorithm{})
+}
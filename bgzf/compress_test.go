@@ -0,0 +1,56 @@
+package bgzf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestGzipCompatible(t *testing.T) {
+	// Random data to get more than one block.
+	exp := make([]byte, 3*maxBlockSize+100)
+	_, _ = rand.New(rand.NewSource(1)).Read(exp)
+
+	a, err := compress.NewAlgorithm("bgzf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasSuffix(encoded, eofMarker) || len(eofMarker) != 28 {
+		t.Error("expected encode to end with the 28 byte EOF marker")
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ioutil.ReadAll(r); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode with gzip doesn't match expected value")
+	}
+
+	// Walk the blocks using BSIZE.
+	blocks := 0
+	for v := encoded; len(v) > 0; blocks++ {
+		if len(v) < 18 || v[12] != 'B' || v[13] != 'C' {
+			t.Fatalf("block %d missing the BC extra field", blocks)
+		}
+		v = v[binary.LittleEndian.Uint16(v[16:])+1:]
+	}
+
+	if blocks != 5 {
+		t.Errorf("expected 4 blocks and the EOF marker got %d", blocks)
+	}
+}
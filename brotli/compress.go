@@ -0,0 +1,282 @@
+package brotli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.DefaultCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
+type brotliAlgorithm struct {
+	compress.Options
+
+	level     compress.Level
+	window    int
+	maxMemory int64
+}
+
+type brotliEncoder struct {
+	writer *brotli.Writer
+}
+
+type brotliDecoder struct {
+	reader    *brotli.Reader
+	maxMemory int64
+}
+
+func (a *brotliAlgorithm) NewAlgorithm() compress.Algorithm {
+	return &brotliAlgorithm{level: compress.DefaultCompression}
+}
+
+func (a *brotliAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *brotliAlgorithm) Name() string {
+	return "brotli"
+}
+
+func (a *brotliAlgorithm) Ext() string {
+	return "br"
+}
+
+func (a *brotliAlgorithm) String() string {
+	return fmt.Sprintf("brotli(level=%d, window=%d)", a.level, a.window)
+}
+
+func (a *brotliAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapFlush
+}
+
+func (a *brotliAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+// DefaultLevel brotli quality 6.
+func (a *brotliAlgorithm) DefaultLevel() compress.Level {
+	return 6
+}
+
+func (a *brotliAlgorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm brotli level %d", level)
+	}
+	a.level = level
+	return nil
+}
+
+func (a *brotliAlgorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetWindow(bits int) error {
+	if bits < 10 || bits > 24 {
+		return errors.Wrapf(compress.ErrInvalidWindowSize, "algorithm brotli window %d must be between 10 and 24", bits)
+	}
+	a.window = bits
+	return nil
+}
+
+func (a *brotliAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm brotli")
+}
+
+// SetDeterministic is a no-op, the output of brotli is always the same for the same input.
+func (a *brotliAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *brotliAlgorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+// SetNoChecksum is a no-op, brotli has no checksum.
+func (a *brotliAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return nil
+}
+
+func (a *brotliAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm brotli")
+}
+
+func (a *brotliAlgorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm brotli")
+}
+
+// SetMaxMemory decoder rejects streams with a window larger than n.
+func (a *brotliAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm brotli max memory %d", n)
+	}
+	a.maxMemory = n
+	return nil
+}
+
+// quality maps a generic compression level onto the brotli quality 0-11.
+func quality(level compress.Level) int {
+	switch level {
+	case compress.NoCompression, compress.DefaultCompression:
+		return brotli.DefaultCompression
+	case compress.BestSpeed:
+		return brotli.BestSpeed
+	case compress.BestCompression:
+		return brotli.BestCompression
+	}
+	return int(level)
+}
+
+func (a *brotliAlgorithm) Validate() error {
+	return nil
+}
+
+func (a *brotliAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	return &brotliEncoder{
+		writer: brotli.NewWriterOptions(w, brotli.WriterOptions{
+			Quality: quality(a.level),
+			LGWin:   a.window,
+		}),
+	}, nil
+}
+
+func (a *brotliAlgorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+func (e *brotliEncoder) Write(v []byte) (int, error) {
+	return e.writer.Write(v)
+}
+
+func (e *brotliEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
+}
+
+func (e *brotliEncoder) Reset(w io.Writer) error {
+	e.writer.Reset(w)
+	return nil
+}
+
+func (e *brotliEncoder) Flush() error {
+	return e.writer.Flush()
+}
+
+func (e *brotliEncoder) Close() error {
+	return e.writer.Close()
+}
+
+func (a *brotliAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	d := &brotliDecoder{maxMemory: a.maxMemory}
+	r, err := d.limit(r)
+	if err != nil {
+		return nil, err
+	}
+	d.reader = brotli.NewReader(r)
+	return d, nil
+}
+
+// windowSize of the ring buffer from WBITS at the start of the stream, see RFC 7932 section 9.1.
+func windowSize(b byte) int64 {
+	bits := uint(16)
+	switch {
+	case b&1 == 0:
+	case (b>>1)&7 != 0:
+		bits = 17 + uint((b>>1)&7)
+	case (b>>4)&7 == 1:
+		// Large window brotli, up to 30 bits.
+		bits = 30
+	case (b>>4)&7 != 0:
+		bits = 8 + uint((b>>4)&7)
+	default:
+		bits = 17
+	}
+	return 1<<bits - 16
+}
+
+// limit peek at the window size before brotli allocates it.
+func (d *brotliDecoder) limit(r io.Reader) (io.Reader, error) {
+	if d.maxMemory == 0 {
+		return r, nil
+	}
+	br := bufio.NewReader(r)
+	b, err := br.Peek(1)
+	if err != nil {
+		// Let brotli return the error.
+		return br, nil
+	}
+	if n := windowSize(b[0]); n > d.maxMemory {
+		return nil, errors.Wrapf(compress.ErrMemoryLimit, "algorithm brotli window %d exceeds max memory %d", n, d.maxMemory)
+	}
+	return br, nil
+}
+
+func (a *brotliAlgorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *brotliDecoder) Read(v []byte) (int, error) {
+	return d.reader.Read(v)
+}
+
+func (d *brotliDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *brotliDecoder) Reset(r io.Reader) error {
+	r, err := d.limit(r)
+	if err != nil {
+		return err
+	}
+	return d.reader.Reset(r)
+}
+
+func (d *brotliDecoder) Close() error {
+	return nil
+}
+
+func init() {
+	compress.Register("brotli", &brotliAlgorithm{})
+}
@@ -0,0 +1,116 @@
+package brotli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+	_ "github.com/mickep76/compress/gzip"
+	_ "github.com/mickep76/compress/snappy"
+)
+
+func TestSetWindow(t *testing.T) {
+	for _, bits := range []int{9, 25} {
+		if _, err := compress.NewAlgorithm("brotli", compress.WithWindow(bits)); err == nil {
+			t.Errorf("window %d should be out of range", bits)
+		}
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	a, err := compress.NewAlgorithm("brotli")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(bytes.Repeat([]byte("abc123\ndef456\n"), 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Decode(encoded[:len(encoded)/2]); err == nil {
+		t.Error("decoding truncated input should return an error")
+	}
+}
+
+func TestMaxMemory(t *testing.T) {
+	a, err := compress.NewAlgorithm("brotli", compress.WithWindow(22))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := compress.NewAlgorithm("brotli", compress.WithMaxMemory(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Decode(encoded); errors.Cause(err) != compress.ErrMemoryLimit {
+		t.Errorf("expected %v got %v", compress.ErrMemoryLimit, err)
+	}
+
+	c, err := compress.NewAlgorithm("brotli", compress.WithMaxMemory(4<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+}
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.BR"); err != nil {
+		t.Error(err)
+	} else if name != "brotli" {
+		t.Errorf("expected brotli got %s", name)
+	}
+}
+
+func TestEncodeBest(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\nghi789\n"), 1000)
+	name, encoded, err := compress.EncodeBest(exp, "snappy", "gzip", "brotli")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "brotli" {
+		t.Errorf("expected brotli to be best got %s", name)
+	}
+
+	a, err := compress.NewAlgorithm(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded output doesn't match original")
+	}
+
+	if _, _, err := compress.EncodeBest(exp, "brotli", "foo"); err == nil {
+		t.Error("foo should not be a registered algorithm")
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.DefaultCompression, compress.BestSpeed, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("brotli", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{compress.HuffmanOnly, compress.NoCompression, 10} {
+		if _, err := compress.NewAlgorithm("brotli", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package bzip2
+
+import (
+	"compress/bzip2"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// bzip2Algorithm is decode-only since the standard library doesn't provide a
+// bzip2 compressor.
+type bzip2Algorithm struct {
+	compress.Options
+}
+
+type bzip2Decoder struct {
+	reader io.Reader
+}
+
+func (a *bzip2Algorithm) NewAlgorithm() compress.Algorithm {
+	return &bzip2Algorithm{}
+}
+
+func (a *bzip2Algorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *bzip2Algorithm) Name() string {
+	return "bzip2"
+}
+
+func (a *bzip2Algorithm) Ext() string {
+	return "bz2"
+}
+
+func (a *bzip2Algorithm) String() string {
+	return "bzip2"
+}
+
+func (a *bzip2Algorithm) Capabilities() compress.Capability {
+	return compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *bzip2Algorithm) ValidLevels() []compress.Level {
+	return nil
+}
+
+func (a *bzip2Algorithm) DefaultLevel() compress.Level {
+	return compress.NoCompression
+}
+
+func (a *bzip2Algorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm bzip2 level %d", level)
+	}
+	return nil
+}
+
+func (a *bzip2Algorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm bzip2")
+}
+
+// SetDeterministic is a no-op, the output of bzip2 is always the same for the same input.
+func (a *bzip2Algorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *bzip2Algorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+func (a *bzip2Algorithm) SetNoChecksum(noChecksum bool) error {
+	return errors.Wrap(compress.ErrChecksumToggleUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm bzip2")
+}
+
+// SetMaxMemory is a no-op, the block buffers are at most 900KB.
+func (a *bzip2Algorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm bzip2 max memory %d", n)
+	}
+	return nil
+}
+
+func (a *bzip2Algorithm) Validate() error {
+	return nil
+}
+
+func (a *bzip2Algorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	return nil, errors.Wrap(compress.ErrEncodeUnsupported, "algorithm bzip2")
+}
+
+func (a *bzip2Algorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+func (a *bzip2Algorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	return &bzip2Decoder{reader: bzip2.NewReader(r)}, nil
+}
+
+func (a *bzip2Algorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *bzip2Decoder) Read(v []byte) (int, error) {
+	return d.reader.Read(v)
+}
+
+func (d *bzip2Decoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *bzip2Decoder) Reset(r io.Reader) error {
+	d.reader = bzip2.NewReader(r)
+	return nil
+}
+
+func (d *bzip2Decoder) Close() error {
+	return nil
+}
+
+func init() {
+	compress.Register("bzip2", &bzip2Algorithm{})
+}
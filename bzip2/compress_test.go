@@ -0,0 +1,41 @@
+package bzip2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeUnsupported(t *testing.T) {
+	a, err := compress.NewAlgorithm("bzip2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.NewEncoder(&bytes.Buffer{}); errors.Cause(err) != compress.ErrEncodeUnsupported {
+		t.Errorf("expected encode unsupported error, got: %v", err)
+	}
+
+	if _, err := a.Encode([]byte("abc123")); errors.Cause(err) != compress.ErrEncodeUnsupported {
+		t.Errorf("expected encode unsupported error, got: %v", err)
+	}
+}
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.BZ2"); err != nil {
+		t.Error(err)
+	} else if name != "bzip2" {
+		t.Errorf("expected bzip2 got %s", name)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.DefaultCompression, compress.BestSpeed} {
+		if _, err := compress.NewAlgorithm("bzip2", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
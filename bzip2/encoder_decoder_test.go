@@ -0,0 +1,34 @@
+package bzip2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestDecode(t *testing.T) {
+	exp, err := ioutil.ReadFile("testdata/abc.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := ioutil.ReadFile("testdata/abc.txt.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := compress.NewAlgorithm("bzip2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(errors.Wrap(err, "test decode"))
+	} else if !bytes.Equal(exp, got) {
+		t.Error(errors.Errorf("test decode doesn't match expected value"))
+	}
+}
@@ -0,0 +1,92 @@
+package compress
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/mickep76/compress/internal/trailer"
+)
+
+// checksumTrailer size of the trailer at the end of each member and how to parse the checksum in it.
+type checksumTrailer struct {
+	size  int
+	parse func(v []byte) uint32
+}
+
+// crc32ISIZE CRC32 followed by ISIZE.
+func crc32ISIZE(v []byte) uint32 {
+	return binary.LittleEndian.Uint32(v[:4])
+}
+
+// trailers algorithms storing the checksum of the original data at the end of the stream.
+var trailers = map[string]checksumTrailer{
+	"bgzf": {8, crc32ISIZE},
+	"gzip": {8, crc32ISIZE},
+	// Adler32.
+	"zlib": {4, binary.BigEndian.Uint32},
+}
+
+// DecodeChecksum algorithm returning the checksum embedded in the stream, CRC32 for bgzf and gzip and Adler32 for zlib.
+// For concatenated streams it's the CRC32 of the last member with data, data after a zlib stream is ignored.
+// For algorithms without an embedded checksum the decoded data is returned with checksum 0 and ErrChecksumUnsupported.
+func DecodeChecksum(a Algorithm, v []byte) ([]byte, uint32, error) {
+	t, ok := trailers[a.Name()]
+	if !ok {
+		plaintext, err := Decode(a, v)
+		if err != nil {
+			return nil, 0, err
+		}
+		return plaintext, 0, ErrChecksumUnsupported
+	}
+
+	// Verifying wraps the input in a buffered trailer reader of its own which reads ahead of this one,
+	// the decoders check the checksum regardless.
+	c := a.Clone()
+	if err := c.SetChecksumVerify(false); err != nil {
+		return nil, 0, err
+	}
+
+	// The decoder reads the trailer through r byte by byte, so the last bytes read are the trailer.
+	r := trailer.NewReader(bytes.NewReader(v), t.size, t.parse)
+	d, err := c.NewDecoder(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf bytes.Buffer
+	var sum uint32
+	ms, multistream := d.(multistreamDecoder)
+	for first := true; ; first = false {
+		if multistream {
+			ms.Multistream(false)
+		}
+
+		n := buf.Len()
+		if err := copyBuffer(&buf, d, c.HelperOptions().bufferSize); err != nil {
+			_ = d.Close()
+			return nil, 0, err
+		}
+		if first || buf.Len() > n {
+			sum = r.Checksum()
+		}
+
+		if !multistream {
+			break
+		}
+		if err := d.Reset(r); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := d.Close(); err != nil {
+		return nil, 0, err
+	}
+
+	if buf.Len() == 0 {
+		return []byte{}, sum, nil
+	}
+	return buf.Bytes(), sum, nil
+}
@@ -0,0 +1,80 @@
+package compress_test
+
+import (
+	"bytes"
+	"hash/adler32"
+	"hash/crc32"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestDecodeChecksum(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, sum, err := compress.DecodeChecksum(a, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	if crc := crc32.ChecksumIEEE(exp); sum != crc {
+		t.Errorf("checksum expected %08x got %08x", crc, sum)
+	}
+
+	s, err := compress.NewAlgorithm("store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, sum, err = compress.DecodeChecksum(s, exp); err != compress.ErrChecksumUnsupported || sum != 0 {
+		t.Errorf("expected %v and 0 got %v and %08x", compress.ErrChecksumUnsupported, err, sum)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+}
+
+func TestDecodeChecksumTrailer(t *testing.T) {
+	first, last := []byte("abc123\ndef456\n"), bytes.Repeat([]byte("ghi789\n"), 100)
+
+	// Data after a zlib stream isn't part of it.
+	z := compress.MustNewAlgorithm("zlib", compress.WithChecksumVerify(true))
+	encoded, err := z.Encode(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, sum, err := compress.DecodeChecksum(z, append(encoded, "trailing"...)); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(last, got) || sum != adler32.Checksum(last) {
+		t.Errorf("zlib: expected adler32 %08x got %08x", adler32.Checksum(last), sum)
+	}
+
+	// Concatenated members and bgzf blocks ending with the EOF marker return the checksum of the last data.
+	for _, name := range []string{"gzip", "bgzf"} {
+		a := compress.MustNewAlgorithm(name)
+		encoded, err := compress.EncodeRecords(a, [][]byte{first, last})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, sum, err := compress.DecodeChecksum(a, encoded); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !bytes.Equal(append(append([]byte{}, first...), last...), got) {
+			t.Errorf("%s: decode doesn't match expected value", name)
+		} else if sum != crc32.ChecksumIEEE(last) {
+			t.Errorf("%s: expected crc32 %08x got %08x", name, crc32.ChecksumIEEE(last), sum)
+		}
+	}
+}
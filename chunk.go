@@ -0,0 +1,124 @@
+package compress
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// EncodeChunked algorithm splitting v into chunks of at most chunkSize bytes, each encoded as a separate stream
+// that decodes on its own, for parallel decode or reading part of the data. Empty input is a single chunk.
+// Algorithms that can't encode, such as bzip2, return ErrEncodeUnsupported.
+func EncodeChunked(a Algorithm, v []byte, chunkSize int) ([][]byte, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("%w: chunk size %d", ErrInvalidBlockSize, chunkSize)
+	}
+
+	var buf bytes.Buffer
+	e, err := a.NewEncoder(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := [][]byte{}
+	for {
+		n := chunkSize
+		if n > len(v) {
+			n = len(v)
+		}
+
+		if _, err := e.Write(v[:n]); err != nil {
+			return nil, err
+		}
+		if err := e.Close(); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, append([]byte(nil), buf.Bytes()...))
+
+		v = v[n:]
+		if len(v) == 0 {
+			break
+		}
+
+		// Reset starts a new stream without the history of the previous chunk.
+		buf.Reset()
+		if err := e.Reset(&buf); err != nil {
+			return nil, err
+		}
+	}
+
+	return chunks, nil
+}
+
+// DecodeChunkedParallel algorithm decoding chunks from EncodeChunked with workers goroutines and joining
+// them in order, workers <= 0 uses GOMAXPROCS. The first error stops the remaining chunks and is returned.
+// Fewer workers are used when the limit set by SetMaxGoroutines is reached.
+func DecodeChunkedParallel(a Algorithm, chunks [][]byte, workers int) ([]byte, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	decoded := make([][]byte, len(chunks))
+	jobs := make(chan int)
+	done := make(chan struct{})
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		// The first worker waits for a slot, more are only added while the limit allows.
+		var release func()
+		if i == 0 {
+			release = acquire()
+		} else if r, ok := tryAcquire(); ok {
+			release = r
+		} else {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+			for i := range jobs {
+				v, err := a.Decode(chunks[i])
+				if err != nil {
+					once.Do(func() {
+						firstErr = fmt.Errorf("chunk %d: %w", i, err)
+						close(done)
+					})
+					return
+				}
+				decoded[i] = v
+			}
+		}()
+	}
+
+send:
+	for i := range chunks {
+		select {
+		case jobs <- i:
+		case <-done:
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	size := 0
+	for _, v := range decoded {
+		size += len(v)
+	}
+	v := make([]byte, 0, size)
+	for _, d := range decoded {
+		v = append(v, d...)
+	}
+	return v, nil
+}
@@ -0,0 +1,85 @@
+package compress_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeChunked(t *testing.T) {
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+	for _, name := range algorithms() {
+		a := compress.MustNewAlgorithm(name)
+		chunks, err := compress.EncodeChunked(a, v, 4096)
+		if errors.Cause(err) == compress.ErrEncodeUnsupported {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if exp := (len(v) + 4095) / 4096; len(chunks) != exp {
+			t.Errorf("%s: expected %d chunks got %d", name, exp, len(chunks))
+		}
+
+		for i, chunk := range chunks {
+			end := (i + 1) * 4096
+			if end > len(v) {
+				end = len(v)
+			}
+			if got, err := a.Decode(chunk); err != nil {
+				t.Errorf("%s: chunk %d: %v", name, i, err)
+			} else if !bytes.Equal(v[i*4096:end], got) {
+				t.Errorf("%s: chunk %d doesn't match the plaintext", name, i)
+			}
+		}
+	}
+
+	if _, err := compress.EncodeChunked(compress.MustNewAlgorithm("gzip"), v, 0); !errors.Is(err, compress.ErrInvalidBlockSize) {
+		t.Errorf("expected %v got %v", compress.ErrInvalidBlockSize, err)
+	}
+}
+
+func TestDecodeChunkedParallel(t *testing.T) {
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 10000)
+	for _, name := range []string{"gzip", "zstd", "lz4"} {
+		a := compress.MustNewAlgorithm(name)
+		chunks, err := compress.EncodeChunked(a, v, 8192)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var exp []byte
+		for _, chunk := range chunks {
+			decoded, err := a.Decode(chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exp = append(exp, decoded...)
+		}
+
+		for _, workers := range []int{0, 1, 4, 100} {
+			if got, err := compress.DecodeChunkedParallel(a, chunks, workers); err != nil {
+				t.Errorf("%s: %v", name, err)
+			} else if !bytes.Equal(exp, got) {
+				t.Errorf("%s: parallel decode with %d workers doesn't match sequential decode", name, workers)
+			}
+		}
+
+		compress.SetMaxGoroutines(1)
+		if got, err := compress.DecodeChunkedParallel(a, chunks, 4); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("%s: parallel decode with a goroutine limit doesn't match sequential decode", name)
+		}
+		compress.SetMaxGoroutines(0)
+
+		chunks[3] = chunks[3][:len(chunks[3])/2]
+		if _, err := compress.DecodeChunkedParallel(a, chunks, 4); err == nil {
+			t.Errorf("%s: expected an error for a truncated chunk", name)
+		}
+	}
+}
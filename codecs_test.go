@@ -0,0 +1,592 @@
+package compress_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+	_ "github.com/mickep76/compress/bgzf"
+	_ "github.com/mickep76/compress/brotli"
+	_ "github.com/mickep76/compress/bzip2"
+	_ "github.com/mickep76/compress/flate"
+	_ "github.com/mickep76/compress/gzip"
+	_ "github.com/mickep76/compress/lz4"
+	_ "github.com/mickep76/compress/lzw"
+	_ "github.com/mickep76/compress/s2"
+	_ "github.com/mickep76/compress/snappy"
+	_ "github.com/mickep76/compress/store"
+	_ "github.com/mickep76/compress/xz"
+	_ "github.com/mickep76/compress/zlib"
+	_ "github.com/mickep76/compress/zstd"
+)
+
+// algorithms registered by the subpackages, without the mock registered by the internal tests.
+func algorithms() []string {
+	var names []string
+	for _, name := range compress.Algorithms() {
+		if name != "mock" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+var benchPayload = bytes.Repeat([]byte("abc123\ndef456\n"), 64)
+
+func TestDecodeAny(t *testing.T) {
+	// The mock registered by the internal tests decodes anything.
+	restore := compress.Snapshot()
+	defer restore()
+	compress.Unregister("mock")
+
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	for _, name := range []string{"gzip", "flate"} {
+		a, err := compress.NewAlgorithm(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := compress.DecodeAny(encoded); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("%s: decode doesn't match expected value", name)
+		}
+	}
+
+	random := make([]byte, 1024)
+	_, _ = rand.New(rand.NewSource(1)).Read(random)
+	if _, err := compress.DecodeAny(random); err != compress.ErrUnknownFormat {
+		t.Errorf("expected %v got %v", compress.ErrUnknownFormat, err)
+	}
+}
+
+func TestNewAlgorithmLevel(t *testing.T) {
+	for _, name := range algorithms() {
+		a := compress.MustNewAlgorithm(name)
+		s := a.String()
+		i := strings.Index(s, "level=")
+		if i < 0 {
+			continue
+		}
+
+		var level compress.Level
+		if _, err := fmt.Sscanf(s[i+len("level="):], "%d", &level); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !compress.ValidLevel(a, level) {
+			t.Errorf("%s: level %d of a new algorithm isn't valid", name, level)
+		}
+		if level != compress.DefaultCompression {
+			t.Errorf("%s: expected level %d got %s", name, compress.DefaultCompression, s)
+		}
+	}
+}
+
+func TestNewAlgorithmByExt(t *testing.T) {
+	a, err := compress.NewAlgorithmByExt("abc.txt.gz", compress.WithLevel(compress.BestCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Ext() != "gz" {
+		t.Errorf("expected gz got %s", a.Ext())
+	}
+}
+
+func TestMustNewAlgorithm(t *testing.T) {
+	if a := compress.MustNewAlgorithm("gzip", compress.WithLevel(compress.BestSpeed)); a.Name() != "gzip" {
+		t.Errorf("expected gzip got %s", a.Name())
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("unregistered algorithm should panic")
+		} else if !strings.Contains(fmt.Sprint(r), "foo") {
+			t.Errorf("panic should include the algorithm name got %v", r)
+		}
+	}()
+	compress.MustNewAlgorithm("foo")
+}
+
+func TestExtensions(t *testing.T) {
+	exts := compress.Extensions()
+	if exts["gz"] != "gzip" {
+		t.Errorf("expected gz to map to gzip got %q", exts["gz"])
+	}
+
+	delete(exts, "gz")
+	if compress.Extensions()["gz"] != "gzip" {
+		t.Error("extensions should be a fresh copy each call")
+	}
+}
+
+func TestDecodeLimit(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithLevel(compress.BestCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := make([]byte, 1<<20)
+	bomb, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := compress.DecodeLimit(a, bomb, int64(len(exp))); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded output doesn't match original")
+	}
+
+	if _, err := compress.DecodeLimit(a, bomb, int64(len(exp)-1)); err != compress.ErrDecodedSizeExceeded {
+		t.Errorf("expected decoded size exceeded got: %v", err)
+	}
+
+	if got, err := compress.DecodeLimit(a, bomb, math.MaxInt64); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded output doesn't match original with no effective limit")
+	}
+
+	if _, err := compress.DecodeLimit(a, bomb, 0); err != compress.ErrDecodedSizeExceeded {
+		t.Errorf("expected decoded size exceeded got: %v", err)
+	}
+
+	empty, err := a.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := compress.DecodeLimit(a, empty, 0); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("expected empty output got %q, %v", got, err)
+	}
+
+	if _, err := compress.DecodeLimit(a, bomb, -1); !errors.Is(err, compress.ErrInvalidLimit) {
+		t.Errorf("expected %v got %v", compress.ErrInvalidLimit, err)
+	}
+}
+
+func TestEndianUnsupported(t *testing.T) {
+	for _, name := range []string{"gzip", "zlib", "flate"} {
+		if _, err := compress.NewAlgorithm(name, compress.WithEndian(compress.Big)); errors.Cause(err) != compress.ErrEndianUnsupported {
+			t.Errorf("%s expected %v got %v", name, compress.ErrEndianUnsupported, err)
+		}
+	}
+}
+
+func TestBlockSize(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 50000)
+
+	a, err := compress.NewAlgorithm("lz4", compress.WithBlockSize(256<<10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	if _, err := compress.NewAlgorithm("lz4", compress.WithBlockSize(100<<10)); errors.Cause(err) != compress.ErrInvalidBlockSize {
+		t.Errorf("expected %v got %v", compress.ErrInvalidBlockSize, err)
+	}
+
+	if _, err := compress.NewAlgorithm("gzip", compress.WithBlockSize(256<<10)); errors.Cause(err) != compress.ErrBlockSizeUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrBlockSizeUnsupported, err)
+	}
+}
+
+func TestEncodeDecodeTo(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 100)
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := compress.EncodeTo([]byte("prefix"), a, exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(encoded, []byte("prefix")) {
+		t.Fatal("encode to should preserve the existing contents of dst")
+	}
+
+	got, err := compress.DecodeTo([]byte("prefix"), a, encoded[len("prefix"):])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(append([]byte("prefix"), exp...), got) {
+		t.Error("decode to should append the decoded data to dst")
+	}
+}
+
+func TestEncodeDecodePooledBuffer(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exps := [][]byte{bytes.Repeat([]byte("abc123\n"), 1000), bytes.Repeat([]byte("def456\n"), 10)}
+	var encoded, decoded [][]byte
+	for _, exp := range exps {
+		v, err := a.Encode(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, v)
+
+		if v, err = a.Decode(v); err != nil {
+			t.Fatal(err)
+		}
+		decoded = append(decoded, v)
+	}
+
+	// Results must not share memory with the pooled buffers used by later calls.
+	for i, exp := range exps {
+		if !bytes.Equal(exp, decoded[i]) {
+			t.Errorf("decoded %d doesn't match expected value", i)
+		}
+
+		if got, err := a.Decode(encoded[i]); err != nil {
+			t.Errorf("encoded %d: %v", i, err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("encoded %d doesn't match expected value", i)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	encoded, err := a.Encode(benchPayload)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := a.Decode(encoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeTo(b *testing.B) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	dst := make([]byte, 0, 4096)
+	for i := 0; i < b.N; i++ {
+		if dst, err = compress.EncodeTo(dst[:0], a, benchPayload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithChecksumVerify(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(bytes.Repeat([]byte("abc123\ndef456\n"), 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compress.Verify(a, encoded); err != nil {
+		t.Error(err)
+	}
+
+	if err := compress.Verify(a, encoded[:len(encoded)-10]); errors.Cause(err) != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v got %v", io.ErrUnexpectedEOF, err)
+	}
+
+	corrupt := append([]byte{}, encoded...)
+	corrupt[len(corrupt)-8] ^= 0xff
+	if err := compress.Verify(a, corrupt); errors.Cause(err) != compress.ErrChecksumMismatch {
+		t.Errorf("expected %v got %v", compress.ErrChecksumMismatch, err)
+	}
+}
+
+func TestWithLevelName(t *testing.T) {
+	tests := []struct {
+		name  string
+		level compress.Level
+	}{
+		{"best-speed", compress.BestSpeed},
+		{"best-compression", compress.BestCompression},
+		{"default", compress.DefaultCompression},
+		{"huffman-only", compress.HuffmanOnly},
+		{"no-compression", compress.NoCompression},
+		{"Best-Speed", compress.BestSpeed},
+		{"5", 5},
+	}
+
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 100)
+	for _, test := range tests {
+		a, err := compress.NewAlgorithm("gzip", compress.WithLevelName(test.name))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		b, err := compress.NewAlgorithm("gzip", compress.WithLevel(test.level))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := a.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if exp, err := b.Encode(v); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("%s: encode doesn't match level %d", test.name, test.level)
+		}
+	}
+
+	for _, name := range []string{"fastest", "10"} {
+		if _, err := compress.NewAlgorithm("gzip", compress.WithLevelName(name)); !errors.Is(err, compress.ErrInvalidLevel) {
+			t.Errorf("%s: expected %v got %v", name, compress.ErrInvalidLevel, err)
+		}
+	}
+}
+
+func TestWithLevelClamp(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithLevelClamp(true), compress.WithLevel(15))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := a.String(); s != "gzip(level=9)" {
+		t.Errorf("expected gzip(level=9) got %s", s)
+	}
+
+	if a, err = compress.NewAlgorithm("gzip", compress.WithLevelClamp(true), compress.WithLevel(-10)); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.String(); s != "gzip(level=-2)" {
+		t.Errorf("expected gzip(level=-2) got %s", s)
+	}
+
+	if _, err := compress.NewAlgorithm("gzip", compress.WithLevel(15)); errors.Cause(err) != compress.ErrInvalidLevel {
+		t.Errorf("expected %v got %v", compress.ErrInvalidLevel, err)
+	}
+
+	if l := compress.ClampLevel(compress.MustNewAlgorithm("lzw"), 15); l != 15 {
+		t.Errorf("expected 15 for an algorithm without levels got %d", l)
+	}
+}
+
+func TestAdaptiveLevel(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithAdaptiveLevel(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	small, large := compress.LevelForSize(a, 100), compress.LevelForSize(a, 2*compress.AdaptiveLargeSize)
+	if small != compress.BestSpeed || large != compress.BestCompression {
+		t.Errorf("expected %d and %d got %d and %d", compress.BestSpeed, compress.BestCompression, small, large)
+	}
+
+	b, err := compress.NewAlgorithm("gzip", compress.WithLevel(compress.BestSpeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 10)
+	exp, err := b.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Encode(v); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("adaptive encode of a small input doesn't match best speed")
+	}
+}
+
+func TestFlushMode(t *testing.T) {
+	for _, name := range []string{"gzip", "zlib", "flate"} {
+		a, err := compress.NewAlgorithm(name, compress.WithFlushMode(compress.FullFlush))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := &bytes.Buffer{}
+		e, err := a.NewEncoder(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Write([]byte("abc")); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		offset := buf.Len()
+		if _, err := e.Write([]byte("def")); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := a.Decode(buf.Bytes()); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(got) != "abcdef" {
+			t.Errorf("%s: expected abcdef got %q", name, got)
+		}
+
+		// Decoding from the offset has no references to the data before the full flush.
+		got, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(buf.Bytes()[offset:])))
+		if err != nil && err != io.ErrUnexpectedEOF {
+			t.Errorf("%s: %v", name, err)
+		}
+		if !bytes.HasPrefix(got, []byte("def")) {
+			t.Errorf("%s: expected def after the full flush got %q", name, got)
+		}
+	}
+
+	a, err := compress.NewAlgorithm("flate", compress.WithFlushMode(compress.NoFlush))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	e, err := a.NewEncoder(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output on flush got %d bytes", buf.Len())
+	}
+
+	if _, err := compress.NewAlgorithm("lz4", compress.WithFlushMode(compress.FullFlush)); errors.Cause(err) != compress.ErrFlushModeUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrFlushModeUnsupported, err)
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	for _, name := range algorithms() {
+		a, err := compress.NewAlgorithm(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, v := range [][]byte{nil, {}} {
+			encoded, err := a.Encode(v)
+			if errors.Cause(err) == compress.ErrEncodeUnsupported {
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			if len(encoded) == 0 && name != "store" {
+				t.Errorf("%s: expected a stream header for empty input", name)
+			}
+
+			if got, err := a.Decode(encoded); err != nil {
+				t.Errorf("%s: %v", name, err)
+			} else if got == nil || len(got) != 0 {
+				t.Errorf("%s: expected an empty slice got %#v", name, got)
+			}
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	gz := compress.MustNewAlgorithm("gzip").Capabilities()
+	if !gz.Has(compress.CapLevel | compress.CapChecksum | compress.CapFlush) {
+		t.Error("gzip should support levels, checksum and flush")
+	}
+	if gz.Has(compress.CapLitWidth) {
+		t.Error("gzip should not support lit width")
+	}
+
+	if !compress.MustNewAlgorithm("lzw").Capabilities().Has(compress.CapLitWidth | compress.CapEndian) {
+		t.Error("lzw should support lit width and endian")
+	}
+
+	// Reported capabilities are accepted and the others rejected, except store which ignores lit width.
+	for _, name := range algorithms() {
+		a := compress.MustNewAlgorithm(name)
+		caps := a.Capabilities()
+		for _, c := range []struct {
+			capability  compress.Capability
+			err         error
+			unsupported error
+		}{
+			{compress.CapLitWidth, a.Clone().SetLitWidth(8), compress.ErrLitWidthUnsupported},
+			{compress.CapEndian, a.Clone().SetEndian(compress.Big), compress.ErrEndianUnsupported},
+			{compress.CapDictionary, a.Clone().SetDictionary([]byte("abc")), compress.ErrDictionaryUnsupported},
+			{compress.CapConcurrency, a.Clone().SetConcurrency(2), compress.ErrConcurrencyUnsupported},
+		} {
+			if caps.Has(c.capability) && errors.Cause(c.err) == c.unsupported {
+				t.Errorf("%s: capability %d is reported but the option returned %v", name, c.capability, c.err)
+			}
+			if !caps.Has(c.capability) && c.err == nil && name != "store" {
+				t.Errorf("%s: capability %d isn't reported but the option is accepted", name, c.capability)
+			}
+		}
+		if levels := len(a.ValidLevels()) > 1; levels != caps.Has(compress.CapLevel) {
+			t.Errorf("%s: capability level is %t with %d levels", name, caps.Has(compress.CapLevel), len(a.ValidLevels()))
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"gzip", ".gz", "gz", "GZ"} {
+		if a, err := compress.Parse(s, compress.WithLevel(compress.BestSpeed)); err != nil {
+			t.Errorf("%s: %v", s, err)
+		} else if a.Name() != "gzip" {
+			t.Errorf("%s: expected gzip got %s", s, a.Name())
+		}
+	}
+
+	_, err := compress.Parse("bogus")
+	if !errors.Is(err, compress.ErrNotRegistered) {
+		t.Fatalf("expected %v got %v", compress.ErrNotRegistered, err)
+	}
+	if !strings.Contains(err.Error(), "gzip") || !strings.Contains(err.Error(), "gz,") {
+		t.Errorf("expected the valid values in the error got %v", err)
+	}
+}
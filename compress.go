@@ -4,38 +4,105 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
-var algorithms = make(map[string]Algorithm)
+var (
+	algorithms = make(map[string]Algorithm)
+	lock       sync.RWMutex
+)
 
 // Algorithm interface.
+// Clone copies the configuration so the copy can be used without sharing mutable state.
+// DefaultLevel the level used unless set, algorithms without levels such as lzw and snappy return NoCompression.
+// String describes the name and configured options, i.e. "gzip(level=9)", dictionaries only by length.
+// Capabilities the features supported, so generic tooling can avoid options the algorithm rejects.
+// HelperOptions the options applied by the helpers in this package rather than the algorithm, see Options.
 type Algorithm interface {
 	NewAlgorithm() Algorithm
+	Clone() Algorithm
+	Name() string
 	Ext() string
+	String() string
+	Capabilities() Capability
 	NewEncoder(w io.Writer) (Encoder, error)
 	NewDecoder(r io.Reader) (Decoder, error)
 	Encode(v []byte) ([]byte, error)
 	Decode(v []byte) ([]byte, error)
+	ValidLevels() []Level
+	DefaultLevel() Level
 	SetLevel(level Level) error
 	SetLitWidth(width int) error
 	SetEndian(endian Endian) error
+	SetWindow(bits int) error
+	SetName(name string) error
+	SetComment(comment string) error
+	SetModTime(modTime time.Time) error
+	SetOS(os byte) error
+	SetExtra(extra []byte) error
+	SetDeterministic(deterministic bool) error
+	SetDictionary(dict []byte) error
+	SetChecksumVerify(verify bool) error
+	SetNoChecksum(noChecksum bool) error
+	SetTrustSize(trust bool) error
+	SetFlushMode(mode FlushMode) error
+	SetConcurrency(n int) error
+	SetBlockSize(n int) error
+	SetMaxMemory(n int64) error
+	Validate() error
+	HelperOptions() *Options
 }
 
 // Encoder interface.
+// ReadFrom encodes r until EOF, avoiding intermediate buffering when io.Copy is used.
+// Flush writes any buffered data without closing the stream, lzw and xz return ErrFlushUnsupported.
+// Reset rebinds the encoder to a new writer, algorithms without native reset support construct a new internal writer.
 type Encoder interface {
 	Write(v []byte) (int, error)
+	ReadFrom(r io.Reader) (int64, error)
+	Flush() error
+	Reset(w io.Writer) error
 	Close() error
 }
 
 // Decoder interface.
+// WriteTo decodes to w until EOF, avoiding intermediate buffering when io.Copy is used.
+// Reset rebinds the decoder to a new reader, algorithms without native reset support construct a new internal reader.
 type Decoder interface {
 	Read(v []byte) (int, error)
+	WriteTo(w io.Writer) (int64, error)
+	Reset(r io.Reader) error
 	Close() error
 }
 
+// DefaultBufferSize copy buffer size used when streaming, unless set using WithBufferSize.
+const DefaultBufferSize = 32 * 1024
+
 // Option variadic function.
 type Option func(Algorithm) error
 
+// Options applied by the helpers in this package rather than the algorithm, such as the progress callback.
+// Algorithms embed it to implement HelperOptions, Clone copies it with the algorithm.
+type Options struct {
+	adaptive   bool
+	clamp      bool
+	bufferSize int
+	progress   func(processed int64)
+	timeout    time.Duration
+}
+
+// HelperOptions the options embedded in the algorithm.
+func (o *Options) HelperOptions() *Options {
+	return o
+}
+
 // Level compression level.
 type Level int
 
@@ -56,6 +123,96 @@ const (
 	HuffmanOnly Level = -2
 )
 
+// levelNames for ParseLevel.
+var levelNames = map[string]Level{
+	"no-compression":   NoCompression,
+	"best-speed":       BestSpeed,
+	"best-compression": BestCompression,
+	"default":          DefaultCompression,
+	"huffman-only":     HuffmanOnly,
+}
+
+// ParseLevel name such as "best-speed", "best-compression", "default", "huffman-only", "no-compression"
+// or a number, returns ErrInvalidLevel for unknown names.
+func ParseLevel(name string) (Level, error) {
+	if level, ok := levelNames[strings.ToLower(name)]; ok {
+		return level, nil
+	}
+
+	n, err := strconv.Atoi(name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidLevel, name)
+	}
+	return Level(n), nil
+}
+
+// ValidLevel is the level valid for the algorithm.
+func ValidLevel(a Algorithm, level Level) bool {
+	for _, l := range a.ValidLevels() {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// ClampLevel to the nearest valid level of the algorithm, the level is returned as is for algorithms without levels.
+func ClampLevel(a Algorithm, level Level) Level {
+	levels := a.ValidLevels()
+	if len(levels) == 0 {
+		return level
+	}
+
+	nearest := levels[0]
+	for _, l := range levels[1:] {
+		if distance(l, level) < distance(nearest, level) {
+			nearest = l
+		}
+	}
+	return nearest
+}
+
+func distance(a, b Level) Level {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+const (
+	// AdaptiveSmallSize inputs up to this size use BestSpeed with WithAdaptiveLevel, the ratio barely matters.
+	AdaptiveSmallSize = 4 * 1024
+
+	// AdaptiveLargeSize inputs from this size use BestCompression with WithAdaptiveLevel.
+	AdaptiveLargeSize = 1024 * 1024
+)
+
+// LevelForSize level WithAdaptiveLevel picks for an input of size bytes, inputs in between the thresholds
+// and algorithms without BestSpeed and BestCompression use the default level.
+func LevelForSize(a Algorithm, size int) Level {
+	switch {
+	case size <= AdaptiveSmallSize && ValidLevel(a, BestSpeed):
+		return BestSpeed
+	case size >= AdaptiveLargeSize && ValidLevel(a, BestCompression):
+		return BestCompression
+	}
+	return a.DefaultLevel()
+}
+
+// FlushMode what Flush on an encoder does.
+type FlushMode int
+
+const (
+	// SyncFlush flush the pending data byte-aligned so it can be decoded, as used with HTTP. Default.
+	SyncFlush FlushMode = iota
+
+	// NoFlush Flush does nothing, data is only written as the encoder needs to and on Close.
+	NoFlush
+
+	// FullFlush sync flush and reset the compression state, decoding can start at the flushed offset.
+	FullFlush
+)
+
 // Endian the order in which bytes are arranged into larger values.
 type Endian int
 
@@ -67,34 +224,115 @@ const (
 	Big Endian = 1
 )
 
-// Register algorithm.
+// Capability feature supported by an algorithm, combined as a bitmask.
+type Capability uint
+
+const (
+	// CapLevel compression levels.
+	CapLevel Capability = 1 << iota
+
+	// CapLitWidth literal code width.
+	CapLitWidth
+
+	// CapEndian bit ordering.
+	CapEndian
+
+	// CapDictionary preset dictionary.
+	CapDictionary
+
+	// CapConcurrency concurrent encoding.
+	CapConcurrency
+
+	// CapFlush flushing pending data on the encoder.
+	CapFlush
+
+	// CapChecksum checksum of the original data in the stream.
+	CapChecksum
+
+	// CapMultistream concatenated streams decode as one.
+	CapMultistream
+)
+
+// Has all of the capabilities in c.
+func (caps Capability) Has(c Capability) bool {
+	return caps&c == c
+}
+
+// Register algorithm, panics if the name is already registered.
 func Register(name string, algorithm Algorithm) {
+	lock.Lock()
+	defer lock.Unlock()
+	if _, ok := algorithms[name]; ok {
+		panic("compress: algorithm already registered: " + name)
+	}
 	algorithms[name] = algorithm
 }
 
-// Algorithms registered.
+// Unregister algorithm, returns false if it wasn't registered.
+func Unregister(name string) bool {
+	lock.Lock()
+	defer lock.Unlock()
+	if _, ok := algorithms[name]; !ok {
+		return false
+	}
+	delete(algorithms, name)
+	return true
+}
+
+// Snapshot registry returning a function restoring it, so tests can register fakes with defer Snapshot()().
+// The map is copied, changes after the snapshot don't affect it.
+func Snapshot() func() {
+	lock.RLock()
+	saved := make(map[string]Algorithm, len(algorithms))
+	for name, a := range algorithms {
+		saved[name] = a
+	}
+	lock.RUnlock()
+
+	return func() {
+		lock.Lock()
+		defer lock.Unlock()
+		algorithms = make(map[string]Algorithm, len(saved))
+		for name, a := range saved {
+			algorithms[name] = a
+		}
+	}
+}
+
+// Algorithms registered, sorted by name.
 func Algorithms() []string {
+	lock.RLock()
+	defer lock.RUnlock()
 	l := []string{}
 	for a := range algorithms {
 		l = append(l, a)
 	}
+	sort.Strings(l)
 	return l
 }
 
+// lookup registered algorithm.
+func lookup(name string) (Algorithm, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	a, ok := algorithms[name]
+	return a, ok
+}
+
 // Registered is the algorithm registered.
 func Registered(name string) error {
-	_, ok := algorithms[name]
+	_, ok := lookup(name)
 	if !ok {
-		return fmt.Errorf("algorithm not registered: %s", name)
+		return fmt.Errorf("%w: %s", ErrNotRegistered, name)
 	}
 	return nil
 }
 
-// NewAlgorithm variadic constructor.
+// NewAlgorithm variadic constructor, the combination of options is checked with Validate.
 func NewAlgorithm(name string, opts ...Option) (Algorithm, error) {
-	a, ok := algorithms[name]
+	a, ok := lookup(name)
 	if !ok {
-		return nil, fmt.Errorf("algorithm not registered: %s", name)
+		return nil, fmt.Errorf("%w: %s", ErrNotRegistered, name)
 	}
 	a = a.NewAlgorithm()
 	for _, opt := range opts {
@@ -102,19 +340,122 @@ func NewAlgorithm(name string, opts ...Option) (Algorithm, error) {
 			return nil, err
 		}
 	}
+
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
 	return a, nil
 }
 
+// MustNewAlgorithm variadic constructor that panics on error.
+func MustNewAlgorithm(name string, opts ...Option) Algorithm {
+	a, err := NewAlgorithm(name, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("compress: new algorithm %s: %v", name, err))
+	}
+	return a
+}
+
+// NewAlgorithmByExt variadic constructor using the algorithm matching the file extension.
+func NewAlgorithmByExt(filename string, opts ...Option) (Algorithm, error) {
+	name, err := DetectByExt(filename)
+	if err != nil {
+		exts := []string{}
+		for ext := range Extensions() {
+			exts = append(exts, ext)
+		}
+		sort.Strings(exts)
+		return nil, fmt.Errorf("%w: no algorithm for extension: %s, known extensions: %s", err, filename, strings.Join(exts, ", "))
+	}
+	return NewAlgorithm(name, opts...)
+}
+
+// Parse variadic constructor accepting either a registered name or an extension with or without the dot,
+// i.e. "gzip", ".gz" or "gz", for command line flags. The error lists the valid values.
+func Parse(s string, opts ...Option) (Algorithm, error) {
+	s = strings.ToLower(s)
+	if _, ok := lookup(s); ok {
+		return NewAlgorithm(s, opts...)
+	}
+
+	exts := Extensions()
+	if name, ok := exts[strings.TrimPrefix(s, ".")]; ok {
+		return NewAlgorithm(name, opts...)
+	}
+
+	valid := Algorithms()
+	for ext := range exts {
+		valid = append(valid, ext)
+	}
+	sort.Strings(valid)
+	return nil, fmt.Errorf("%w: %s, valid values: %s", ErrNotRegistered, s, strings.Join(valid, ", "))
+}
+
+// NewEncoder variadic constructor for an encoder using the named algorithm.
+func NewEncoder(name string, w io.Writer, opts ...Option) (Encoder, error) {
+	a, err := NewAlgorithm(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return a.NewEncoder(w)
+}
+
+// NewDecoder variadic constructor for a decoder using the named algorithm.
+func NewDecoder(name string, r io.Reader, opts ...Option) (Decoder, error) {
+	a, err := NewAlgorithm(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return a.NewDecoder(r)
+}
+
 // WithLevel compression level.
-// Supported by gzip, zlib.
+// Supported by brotli, flate, gzip, lz4, s2, xz, zlib, zstd.
 func WithLevel(level Level) Option {
 	return func(a Algorithm) error {
-		return a.SetLevel(level)
+		return setLevel(a, level)
+	}
+}
+
+// setLevel clamped to the valid levels with WithLevelClamp.
+func setLevel(a Algorithm, level Level) error {
+	if a.HelperOptions().clamp {
+		level = ClampLevel(a, level)
+	}
+	return a.SetLevel(level)
+}
+
+// WithLevelName compression level by name, see ParseLevel, i.e. for config files and flags.
+func WithLevelName(name string) Option {
+	return func(a Algorithm) error {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		return setLevel(a, level)
+	}
+}
+
+// WithLevelClamp levels out of range are set to the nearest valid level instead of ErrInvalidLevel,
+// i.e. 15 is 9 for gzip. It must come before WithLevel, String shows the effective level.
+func WithLevelClamp(clamp bool) Option {
+	return func(a Algorithm) error {
+		a.HelperOptions().clamp = clamp
+		return nil
+	}
+}
+
+// WithAdaptiveLevel pick the level by the input size when encoding, see LevelForSize.
+// Only applies to Encode and EncodeTo where the size is known, it overrides WithLevel.
+func WithAdaptiveLevel(adaptive bool) Option {
+	return func(a Algorithm) error {
+		a.HelperOptions().adaptive = adaptive
+		return nil
 	}
 }
 
 // WithLitWidth the number of bit's to use for literal codes.
-// Supported by lzw.
+// Supported by lzw in the range 2-8, other algorithms return ErrLitWidthUnsupported.
 func WithLitWidth(width int) Option {
 	return func(a Algorithm) error {
 		return a.SetLitWidth(width)
@@ -122,22 +463,219 @@ func WithLitWidth(width int) Option {
 }
 
 // WithEndian either MSB (most significant byte) or LSB (least significant byte).
-// Supported by lzw.
+// Supported by lzw, other algorithms return ErrEndianUnsupported.
 func WithEndian(endian Endian) Option {
 	return func(a Algorithm) error {
 		return a.SetEndian(endian)
 	}
 }
 
+// WithWindow the base 2 logarithm of the sliding window size.
+// Supported by brotli (10-24) and zstd (10-29), other algorithms return ErrWindowSizeUnsupported.
+func WithWindow(bits int) Option {
+	return func(a Algorithm) error {
+		return a.SetWindow(bits)
+	}
+}
+
+// WithWindowSize the sliding window size in bytes, must be a power of two.
+// Same as WithWindow using the base 2 logarithm of n.
+func WithWindowSize(n int) Option {
+	return func(a Algorithm) error {
+		if n <= 0 || n&(n-1) != 0 {
+			return ErrInvalidWindowSize
+		}
+		return a.SetWindow(bits.Len(uint(n)) - 1)
+	}
+}
+
+// WithName original file name stored in the header.
+// Supported by gzip.
+func WithName(name string) Option {
+	return func(a Algorithm) error {
+		return a.SetName(name)
+	}
+}
+
+// WithComment comment stored in the header.
+// Supported by gzip.
+func WithComment(comment string) Option {
+	return func(a Algorithm) error {
+		return a.SetComment(comment)
+	}
+}
+
+// WithModTime modification time stored in the header.
+// Supported by gzip.
+func WithModTime(modTime time.Time) Option {
+	return func(a Algorithm) error {
+		return a.SetModTime(modTime)
+	}
+}
+
+// WithOS operating system in the header, see RFC 1952 for values, by default 255 (unknown).
+// Supported by gzip, other algorithms return ErrHeaderFieldUnsupported.
+func WithOS(os byte) Option {
+	return func(a Algorithm) error {
+		return a.SetOS(os)
+	}
+}
+
+// WithExtra extra field in the header.
+// Supported by gzip, other algorithms return ErrHeaderFieldUnsupported.
+func WithExtra(extra []byte) Option {
+	return func(a Algorithm) error {
+		return a.SetExtra(extra)
+	}
+}
+
+// WithDeterministic byte identical output for the same input, for reproducible builds.
+// For gzip the mod time is zeroed and OS set to 255 (unknown), other algorithms are already deterministic.
+func WithDeterministic(deterministic bool) Option {
+	return func(a Algorithm) error {
+		return a.SetDeterministic(deterministic)
+	}
+}
+
+// WithDictionary preset dictionary, the same dictionary must be used for encode and decode.
+// Supported by flate, zlib and zstd.
+func WithDictionary(dict []byte) Option {
+	return func(a Algorithm) error {
+		return a.SetDictionary(dict)
+	}
+}
+
+// WithDictionaryFile preset dictionary read from a file, i.e. trained with "zstd --train".
+// Returns the error reading the file or ErrDictionaryUnsupported.
+func WithDictionaryFile(path string) Option {
+	return func(a Algorithm) error {
+		dict, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return a.SetDictionary(dict)
+	}
+}
+
+// WithChecksumVerify report checksum mismatches on decode as ErrChecksumMismatch.
+// Supported by gzip, zlib and zstd, gzip and zlib return a *ChecksumError with the expected and actual value.
+// This is a no-op for other algorithms.
+func WithChecksumVerify(verify bool) Option {
+	return func(a Algorithm) error {
+		return a.SetChecksumVerify(verify)
+	}
+}
+
+// WithNoChecksum don't compute the checksum of the original data when encoding, for throughput.
+// Supported by lz4, xz and zstd, algorithms without a checksum ignore it and
+// algorithms that always add one, such as gzip, return ErrChecksumToggleUnsupported.
+func WithNoChecksum(noChecksum bool) Option {
+	return func(a Algorithm) error {
+		return a.SetNoChecksum(noChecksum)
+	}
+}
+
+// WithTrustSize preallocate the decode output using the size in the stream, ISIZE in the gzip trailer.
+// The preallocation is capped by the maximum deflate expansion of the input, so a wrong size is safe.
+// Supported by gzip, other algorithms return ErrUnsupportedOption.
+func WithTrustSize(trust bool) Option {
+	return func(a Algorithm) error {
+		return a.SetTrustSize(trust)
+	}
+}
+
+// WithFlushMode what Flush on the encoder does. After a full flush the subsequent data can be decoded with
+// flate from the offset, without the data before it. Supported by flate, gzip and zlib, other algorithms
+// return ErrFlushModeUnsupported.
+func WithFlushMode(mode FlushMode) Option {
+	return func(a Algorithm) error {
+		return a.SetFlushMode(mode)
+	}
+}
+
+// WithConcurrency number of goroutines used for encoding, n <= 0 uses GOMAXPROCS.
+// Supported by lz4, s2 and zstd, other algorithms return ErrConcurrencyUnsupported.
+func WithConcurrency(n int) Option {
+	return func(a Algorithm) error {
+		return a.SetConcurrency(n)
+	}
+}
+
+// WithBufferSize copy buffer size used when streaming, must be larger than zero.
+func WithBufferSize(n int) Option {
+	return func(a Algorithm) error {
+		if n <= 0 {
+			return ErrInvalidBufferSize
+		}
+		a.HelperOptions().bufferSize = n
+		return nil
+	}
+}
+
+// WithBlockSize the block size in bytes for block oriented algorithms, larger blocks improve the ratio
+// and smaller blocks the latency. Supported by lz4 (64KB, 256KB, 1MB or 4MB), s2 (4KB-4MB), xz and zstd
+// (power of two 1KB-512MB, sets the window), other algorithms return ErrBlockSizeUnsupported.
+func WithBlockSize(n int) Option {
+	return func(a Algorithm) error {
+		return a.SetBlockSize(n)
+	}
+}
+
+// WithMaxMemory decoders fail with ErrMemoryLimit if the stream needs internal buffers, such as the window,
+// larger than n bytes. Enforced by brotli and zstd, other algorithms have fixed small buffers and ignore it.
+func WithMaxMemory(n int64) Option {
+	return func(a Algorithm) error {
+		return a.SetMaxMemory(n)
+	}
+}
+
+// WithProgress callback invoked with the number of input bytes processed so far when streaming.
+// It's called at most once per buffer size and when the input is exhausted, nil disables it.
+func WithProgress(fn func(processed int64)) Option {
+	return func(a Algorithm) error {
+		a.HelperOptions().progress = fn
+		return nil
+	}
+}
+
+// WithTimeout abort streaming encode and decode with ErrTimeout if it takes longer than d, it's checked
+// between chunks. With EncodeContext and DecodeContext the shorter of d and the context deadline applies.
+func WithTimeout(d time.Duration) Option {
+	return func(a Algorithm) error {
+		a.HelperOptions().timeout = d
+		return nil
+	}
+}
+
 // Encode algorithm.
 func Encode(a Algorithm, v []byte) ([]byte, error) {
-	var buf bytes.Buffer
-	e, err := a.NewEncoder(&buf)
+	return EncodeTo(nil, a, v)
+}
+
+// EncodeTo algorithm appending the encoded data to dst, dst is grown as needed.
+func EncodeTo(dst []byte, a Algorithm, v []byte) ([]byte, error) {
+	if a.HelperOptions().adaptive {
+		if level := LevelForSize(a, len(v)); ValidLevel(a, level) {
+			a = a.Clone()
+			if err := a.SetLevel(level); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	e, err := a.NewEncoder(buf)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := e.Write(v); err != nil {
+	if a.HelperOptions().progress == nil {
+		if _, err := e.Write(v); err != nil {
+			return nil, err
+		}
+	} else if err := copyBuffer(e, withProgress(a, bytes.NewReader(v)), bufferSize(a)); err != nil {
 		return nil, err
 	}
 
@@ -145,24 +683,129 @@ func Encode(a Algorithm, v []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return buf.Bytes(), nil
+	return append(dst, buf.Bytes()...), nil
 }
 
 // Decode algorithm.
 func Decode(a Algorithm, v []byte) ([]byte, error) {
+	return DecodeTo(nil, a, v)
+}
+
+// DecodeTo algorithm appending the decoded data to dst, dst is grown as needed.
+func DecodeTo(dst []byte, a Algorithm, v []byte) ([]byte, error) {
+	d, err := a.NewDecoder(withProgress(a, bytes.NewBuffer(v)))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if s, ok := a.(decodedSizer); ok {
+		if n, ok := s.DecodedSize(v); ok {
+			buf.Grow(capDecodedSize(n, len(v)))
+		}
+	}
+
+	if err := copyBuffer(buf, d, a.HelperOptions().bufferSize); err != nil {
+		return nil, err
+	}
+
+	if err := d.Close(); err != nil {
+		return nil, err
+	}
+
+	if dst == nil {
+		// Empty input decodes to an empty slice, not nil.
+		dst = []byte{}
+	}
+	return append(dst, buf.Bytes()...), nil
+}
+
+// DecodeLimit algorithm returning ErrDecodedSizeExceeded if the output is larger than max bytes.
+// The limit is enforced while decoding, guarding against decompression bombs. A negative max returns
+// ErrInvalidLimit.
+func DecodeLimit(a Algorithm, v []byte, max int64) ([]byte, error) {
+	if max < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLimit, max)
+	}
+
 	d, err := a.NewDecoder(bytes.NewBuffer(v))
 	if err != nil {
 		return nil, err
 	}
 
+	// Read one byte past the limit to know if it's exceeded, there is no byte past math.MaxInt64.
+	var r io.Reader = d
+	if max < math.MaxInt64 {
+		r = io.LimitReader(d, max+1)
+	}
+
 	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, d); err != nil {
+	if err := copyBuffer(&buf, r, a.HelperOptions().bufferSize); err != nil {
+		_ = d.Close()
 		return nil, err
 	}
 
+	if int64(buf.Len()) > max {
+		_ = d.Close()
+		return nil, ErrDecodedSizeExceeded
+	}
+
 	if err := d.Close(); err != nil {
 		return nil, err
 	}
 
+	if buf.Len() == 0 {
+		return []byte{}, nil
+	}
 	return buf.Bytes(), nil
 }
+
+// Verify algorithm decoding v without keeping the output, to check the integrity of the payload.
+// Returns the same errors as Decode, such as ErrChecksumMismatch or io.ErrUnexpectedEOF when truncated.
+func Verify(a Algorithm, v []byte) error {
+	d, err := a.NewDecoder(withProgress(a, bytes.NewReader(v)))
+	if err != nil {
+		return err
+	}
+
+	if err := copyBuffer(ioutil.Discard, d, a.HelperOptions().bufferSize); err != nil {
+		_ = d.Close()
+		return err
+	}
+
+	return d.Close()
+}
+
+// bufferSize set for the algorithm or DefaultBufferSize.
+func bufferSize(a Algorithm) int {
+	if n := a.HelperOptions().bufferSize; n > 0 {
+		return n
+	}
+	return DefaultBufferSize
+}
+
+// copyBuffer from r to w reading size bytes at a time, size <= 0 uses io.Copy.
+// Unlike io.CopyBuffer this doesn't defer to io.WriterTo or io.ReaderFrom, which would ignore the size.
+func copyBuffer(w io.Writer, r io.Reader, size int) error {
+	if size <= 0 {
+		_, err := io.Copy(w, r)
+		return err
+	}
+
+	buf := make([]byte, size)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
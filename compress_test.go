@@ -1,28 +1,271 @@
 package compress
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 )
 
-type algorithm struct{}
+type algorithm struct {
+	Options
+}
+
+func (a *algorithm) NewAlgorithm() Algorithm {
+	return &algorithm{}
+}
+
+func (a *algorithm) Clone() Algorithm {
+	return &algorithm{}
+}
+
+func (a *algorithm) Name() string {
+	return "mock"
+}
+
+func (a *algorithm) Ext() string {
+	return "mock"
+}
+
+func (a *algorithm) String() string {
+	return "mock"
+}
+
+func (a *algorithm) Capabilities() Capability {
+	return 0
+}
+
+func (a *algorithm) ValidLevels() []Level {
+	return []Level{DefaultCompression}
+}
+
+func (a *algorithm) DefaultLevel() Level {
+	return DefaultCompression
+}
+
+func (a *algorithm) SetLevel(level Level) error {
+	return nil
+}
+
+func (a *algorithm) SetLitWidth(width int) error {
+	return nil
+}
+
+func (a *algorithm) SetEndian(endian Endian) error {
+	return nil
+}
+
+func (a *algorithm) SetWindow(bits int) error {
+	return nil
+}
+
+func (a *algorithm) SetName(name string) error {
+	return nil
+}
+
+func (a *algorithm) SetComment(comment string) error {
+	return nil
+}
+
+func (a *algorithm) SetModTime(modTime time.Time) error {
+	return nil
+}
+
+func (a *algorithm) SetOS(os byte) error {
+	return nil
+}
+
+func (a *algorithm) SetExtra(extra []byte) error {
+	return nil
+}
+
+func (a *algorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *algorithm) SetDictionary(dict []byte) error {
+	return nil
+}
+
+func (a *algorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+func (a *algorithm) SetNoChecksum(noChecksum bool) error {
+	return nil
+}
+
+func (a *algorithm) SetTrustSize(trust bool) error {
+	return nil
+}
+
+func (a *algorithm) SetFlushMode(mode FlushMode) error {
+	return nil
+}
+
+func (a *algorithm) SetConcurrency(n int) error {
+	return nil
+}
+
+func (a *algorithm) SetBlockSize(n int) error {
+	return nil
+}
+
+func (a *algorithm) SetMaxMemory(n int64) error {
+	return nil
+}
+
+func (a *algorithm) Validate() error {
+	return nil
+}
 
 func init() {
 	Register("mock", &algorithm{})
 }
 
 func TestRegistered(t *testing.T) {
-	if _, err := Registered("mock"); err != nil {
+	if err := Registered("mock"); err != nil {
 		t.Error(err)
 	}
 
-	if _, err := Registered("foo"); err == nil {
-		t.Error("foo reports as registered when it's not")
+	if err := Registered("foo"); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("foo expected %v got %v", ErrNotRegistered, err)
+	}
+}
+
+func TestNewAlgorithmNotRegistered(t *testing.T) {
+	if _, err := NewAlgorithm("foo"); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("expected %v got %v", ErrNotRegistered, err)
+	}
+
+	if _, err := NewAlgorithmByExt("foo.bar"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected %v got %v", ErrUnknownFormat, err)
 	}
 }
 
 func TestAlgorithms(t *testing.T) {
-	if !reflect.DeepEqual(Algorithms(), []string{"mock"}) {
-		t.Error("registered algorithms got unexpected response")
+	// The external tests register the algorithms in the subpackages as well.
+	l := Algorithms()
+	if i := sort.SearchStrings(l, "mock"); i == len(l) || l[i] != "mock" {
+		t.Errorf("registered algorithms got unexpected response: %v", l)
+	}
+}
+
+// fakeAlgorithm only needs to be registered, not used.
+type fakeAlgorithm struct {
+	Algorithm
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("race%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Register(name, &fakeAlgorithm{})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = Registered(name)
+			_ = Algorithms()
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		Unregister(fmt.Sprintf("race%d", i))
+	}
+}
+
+func TestAlgorithmsSorted(t *testing.T) {
+	for _, name := range []string{"zz", "aa", "mm"} {
+		Register(name, &fakeAlgorithm{})
+	}
+
+	l := Algorithms()
+	if !sort.StringsAreSorted(l) {
+		t.Errorf("algorithms should be sorted: %v", l)
+	}
+
+	for i := 0; i < 10; i++ {
+		if !reflect.DeepEqual(l, Algorithms()) {
+			t.Error("algorithms order isn't stable across calls")
+		}
+	}
+
+	for _, name := range []string{"zz", "aa", "mm"} {
+		Unregister(name)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	Register("fake", &fakeAlgorithm{})
+	if err := Registered("fake"); err != nil {
+		t.Error(err)
+	}
+
+	if !Unregister("fake") {
+		t.Error("fake should have been registered")
+	}
+
+	if err := Registered("fake"); err == nil {
+		t.Error("fake reports as registered after unregister")
+	}
+
+	if Unregister("fake") {
+		t.Error("unregistering a missing algorithm should return false")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	restore := Snapshot()
+	Register("fake", &fakeAlgorithm{})
+	Unregister("mock")
+	if err := Registered("fake"); err != nil {
+		t.Error(err)
+	}
+
+	restore()
+	if err := Registered("fake"); err == nil {
+		t.Error("fake reports as registered after restore")
+	}
+	if err := Registered("mock"); err != nil {
+		t.Errorf("mock should be registered after restore: %v", err)
+	}
+
+	// Restoring twice doesn't share the map with the registry.
+	Register("fake", &fakeAlgorithm{})
+	restore()
+	if err := Registered("fake"); err == nil {
+		t.Error("fake reports as registered after the second restore")
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering mock twice should panic")
+		}
+	}()
+	Register("mock", &algorithm{})
+}
+
+func TestCapDecodedSize(t *testing.T) {
+	for _, c := range []struct {
+		size int64
+		n    int
+		exp  int
+	}{
+		{1000, 100, 1000},
+		{0xffffffff, 20, 20 * maxExpansion},
+		{0xffffffff, 1 << 20, maxPreallocate},
+	} {
+		if got := capDecodedSize(c.size, c.n); got != c.exp {
+			t.Errorf("size %d of %d bytes expected %d got %d", c.size, c.n, c.exp, got)
+		}
 	}
 }
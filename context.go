@@ -0,0 +1,97 @@
+package compress
+
+import (
+	"context"
+	"io"
+)
+
+// EncodeContext algorithm streaming from r to w, checking for cancellation between chunks.
+// On cancellation the encoder is closed and ctx.Err() returned.
+func EncodeContext(ctx context.Context, a Algorithm, r io.Reader, w io.Writer) error {
+	e, err := a.NewEncoder(w)
+	if err != nil {
+		return err
+	}
+
+	tctx, cancel := withTimeout(ctx, a)
+	defer cancel()
+
+	if err := copyContext(tctx, e, withProgress(a, r), bufferSize(a)); err != nil {
+		_ = e.Close()
+		return timeoutErr(ctx, err)
+	}
+
+	return e.Close()
+}
+
+// DecodeContext algorithm streaming from r to w, checking for cancellation between chunks.
+// On cancellation the decoder is closed and ctx.Err() returned.
+func DecodeContext(ctx context.Context, a Algorithm, r io.Reader, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	d, err := a.NewDecoder(withProgress(a, r))
+	if err != nil {
+		return err
+	}
+
+	tctx, cancel := withTimeout(ctx, a)
+	defer cancel()
+
+	if err := copyContext(tctx, w, d, bufferSize(a)); err != nil {
+		_ = d.Close()
+		return timeoutErr(ctx, err)
+	}
+
+	return d.Close()
+}
+
+// withTimeout ctx with the timeout of the algorithm, if set.
+func withTimeout(ctx context.Context, a Algorithm) (context.Context, context.CancelFunc) {
+	if d := a.HelperOptions().timeout; d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
+
+// timeoutErr returns ErrTimeout if err is from the timeout of the algorithm rather than the parent ctx.
+func timeoutErr(parent context.Context, err error) error {
+	if err == context.DeadlineExceeded && parent.Err() == nil {
+		return ErrTimeout
+	}
+	return err
+}
+
+// copyTimeout copy with the timeout of the algorithm, if set.
+func copyTimeout(a Algorithm, w io.Writer, r io.Reader) error {
+	if a.HelperOptions().timeout <= 0 {
+		return copyBuffer(w, r, a.HelperOptions().bufferSize)
+	}
+
+	ctx, cancel := withTimeout(context.Background(), a)
+	defer cancel()
+	return timeoutErr(context.Background(), copyContext(ctx, w, r, bufferSize(a)))
+}
+
+func copyContext(ctx context.Context, w io.Writer, r io.Reader, size int) error {
+	buf := make([]byte, size)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
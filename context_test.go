@@ -0,0 +1,129 @@
+package compress_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/mickep76/compress"
+)
+
+type cancelReader struct {
+	reads  int
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(v []byte) (int, error) {
+	r.reads++
+	if r.reads == 3 {
+		r.cancel()
+	}
+	for i := range v {
+		v[i] = 'a'
+	}
+	return len(v), nil
+}
+
+func TestEncodeContext(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
+	var buf bytes.Buffer
+	if err := compress.EncodeContext(context.Background(), a, bytes.NewReader(exp), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(buf.Bytes()); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded stream doesn't match original")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &cancelReader{cancel: cancel}
+	if err := compress.EncodeContext(ctx, a, r, ioutil.Discard); err != context.Canceled {
+		t.Errorf("expected context canceled got: %v", err)
+	}
+
+	if r.reads != 3 {
+		t.Errorf("expected encoding to stop after 3 reads, got %d", r.reads)
+	}
+}
+
+func TestDecodeContext(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := compress.DecodeContext(context.Background(), a, bytes.NewReader(encoded), &buf); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, buf.Bytes()) {
+		t.Error("decoded stream doesn't match original")
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if err := compress.DecodeContext(ctx, a, bytes.NewReader(encoded), ioutil.Discard); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded got: %v", err)
+	}
+}
+
+// slowReader sleeps before each read.
+type slowReader struct {
+	delay time.Duration
+}
+
+func (r *slowReader) Read(v []byte) (int, error) {
+	time.Sleep(r.delay)
+	for i := range v {
+		v[i] = 'a'
+	}
+	return len(v), nil
+}
+
+func TestWithTimeout(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compress.EncodeStream("gzip", &slowReader{delay: 20 * time.Millisecond}, ioutil.Discard, compress.WithTimeout(50*time.Millisecond)); err != compress.ErrTimeout {
+		t.Errorf("expected %v got %v", compress.ErrTimeout, err)
+	}
+
+	// The shorter of the context deadline and the timeout wins.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := compress.EncodeContext(ctx, a, &slowReader{delay: 20 * time.Millisecond}, ioutil.Discard); err != compress.ErrTimeout {
+		t.Errorf("expected %v got %v", compress.ErrTimeout, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	b, err := compress.NewAlgorithm("gzip", compress.WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compress.EncodeContext(ctx, b, &slowReader{delay: 20 * time.Millisecond}, ioutil.Discard); err != context.DeadlineExceeded {
+		t.Errorf("expected %v got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,118 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type magic struct {
+	name  string
+	match func(b []byte) bool
+}
+
+func prefix(p ...byte) func(b []byte) bool {
+	return func(b []byte) bool {
+		return bytes.HasPrefix(b, p)
+	}
+}
+
+// zlibHeader checks for deflate with a 32K window and a valid header checksum.
+func zlibHeader(b []byte) bool {
+	return len(b) >= 2 && b[0] == 0x78 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+var magics = []magic{
+	{"gzip", prefix(0x1f, 0x8b)},
+	{"zstd", prefix(0x28, 0xb5, 0x2f, 0xfd)},
+	{"lz4", prefix(0x04, 0x22, 0x4d, 0x18)},
+	{"bzip2", prefix(0x42, 0x5a, 0x68)},
+	{"xz", prefix(0xfd, 0x37, 0x7a, 0x58, 0x5a, 0x00)},
+	{"zlib", zlibHeader},
+}
+
+// magicLen longest magic header.
+const magicLen = 6
+
+// Detect algorithm by the magic bytes at the start of the stream.
+// Returns the name the algorithm is registered under and a reader that replays the consumed bytes.
+func Detect(r io.Reader) (string, io.Reader, error) {
+	b := make([]byte, magicLen)
+	n, err := io.ReadFull(r, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	b = b[:n]
+
+	replay := io.MultiReader(bytes.NewReader(b), r)
+	for _, m := range magics {
+		if m.match(b) {
+			return m.name, replay, nil
+		}
+	}
+	return "", replay, ErrUnknownFormat
+}
+
+// DetectByExt algorithm by the file extension, case-insensitive.
+// Only the final extension is considered, i.e. "foo.tar.gz" is detected as gzip.
+func DetectByExt(filename string) (string, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if ext != "" {
+		lock.RLock()
+		defer lock.RUnlock()
+		for name, a := range algorithms {
+			if a.Ext() == ext {
+				return name, nil
+			}
+		}
+	}
+	return "", ErrUnknownFormat
+}
+
+// Extensions registered, mapping extension to algorithm name.
+// Algorithms without extension, like store, aren't included. The map is a copy and safe to modify.
+func Extensions() map[string]string {
+	lock.RLock()
+	defer lock.RUnlock()
+	exts := make(map[string]string, len(algorithms))
+	for name, a := range algorithms {
+		if a.Ext() != "" {
+			exts[a.Ext()] = name
+		}
+	}
+	return exts
+}
+
+// DecodeAnyLimit max decoded size for each algorithm DecodeAny tries.
+const DecodeAnyLimit = 64 * 1024 * 1024
+
+// DecodeAny decode v using the algorithm detected by the magic bytes, if it's inconclusive each registered
+// algorithm is tried in name order and the first that decodes is used. Since formats without magic bytes,
+// such as flate or brotli, can decode arbitrary data it's a last resort for untyped blobs.
+// Each algorithm is limited to DecodeAnyLimit bytes. Returns ErrUnknownFormat if no algorithm decodes v.
+func DecodeAny(v []byte) ([]byte, error) {
+	name, _, err := Detect(bytes.NewReader(v))
+	if err == nil {
+		if a, err := NewAlgorithm(name); err == nil {
+			return DecodeLimit(a, v, DecodeAnyLimit)
+		}
+	}
+
+	for _, name := range Algorithms() {
+		// Store decodes anything unchanged.
+		if name == "store" {
+			continue
+		}
+
+		a, err := NewAlgorithm(name)
+		if err != nil {
+			continue
+		}
+
+		if decoded, err := DecodeLimit(a, v, DecodeAnyLimit); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
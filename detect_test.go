@@ -0,0 +1,66 @@
+package compress
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		file string
+		name string
+	}{
+		{"testdata/abc.txt.gz", "gzip"},
+		{"testdata/abc.txt.zz", "zlib"},
+		{"testdata/abc.txt.zst", "zstd"},
+		{"testdata/abc.txt.lz4", "lz4"},
+		{"testdata/abc.txt.bz2", "bzip2"},
+		{"testdata/abc.txt.xz", "xz"},
+	}
+
+	for _, test := range tests {
+		exp, err := ioutil.ReadFile(test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		name, r, err := Detect(bytes.NewReader(exp))
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+
+		if name != test.name {
+			t.Errorf("%s: expected %s got %s", test.file, test.name, name)
+		}
+
+		if got, err := ioutil.ReadAll(r); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("%s: replayed stream doesn't match input", test.file)
+		}
+	}
+}
+
+func TestDetectUnknown(t *testing.T) {
+	for _, v := range [][]byte{[]byte("abc123\ndef456\n"), {}, {0x1f}} {
+		if _, _, err := Detect(bytes.NewReader(v)); err != ErrUnknownFormat {
+			t.Errorf("expected unknown format for %q got: %v", v, err)
+		}
+	}
+}
+
+func TestDetectByExtUnknown(t *testing.T) {
+	for _, v := range []string{"foo.bogus", "foo", "foo."} {
+		if _, err := DetectByExt(v); err != ErrUnknownFormat {
+			t.Errorf("expected unknown format for %s got: %v", v, err)
+		}
+	}
+}
+
+func TestNewAlgorithmByExtUnknown(t *testing.T) {
+	if _, err := NewAlgorithmByExt("foo.bogus"); err == nil {
+		t.Error("foo.bogus should not match an algorithm")
+	}
+}
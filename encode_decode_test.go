@@ -3,6 +3,7 @@ package compress
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 	"testing"
 )
 
@@ -10,24 +11,29 @@ type encoder struct {
 	writer io.Writer
 }
 
-func (a *algorithm) NewEncoder(w io.Writer, opts ...EncoderOption) (Encoder, error) {
+func (a *algorithm) NewEncoder(w io.Writer) (Encoder, error) {
 	return &encoder{writer: w}, nil
 }
 
-func (e *encoder) SetEndian(endian Endian) error {
-	return nil
+func (a *algorithm) Encode(v []byte) ([]byte, error) {
+	return Encode(a, v)
 }
 
-func (e *encoder) SetLitWidth(width int) error {
-	return nil
+func (e *encoder) Write(v []byte) (int, error) {
+	return e.writer.Write(v)
+}
+
+func (e *encoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
 }
 
-func (e *encoder) SetLevel(level Level) error {
+func (e *encoder) Reset(w io.Writer) error {
+	e.writer = w
 	return nil
 }
 
-func (e *encoder) Write(v []byte) (int, error) {
-	return e.writer.Write(v)
+func (e *encoder) Flush() error {
+	return nil
 }
 
 func (e *encoder) Close() error {
@@ -38,22 +44,27 @@ type decoder struct {
 	reader io.Reader
 }
 
-func (a *algorithm) NewDecoder(r io.Reader, opts ...DecoderOption) (Decoder, error) {
+func (a *algorithm) NewDecoder(r io.Reader) (Decoder, error) {
 	return &decoder{reader: r}, nil
 }
 
-func (d *decoder) SetOrder(o int) error {
-	return nil
-}
-
-func (d *decoder) SetLitWidth(w int) error {
-	return nil
+func (a *algorithm) Decode(v []byte) ([]byte, error) {
+	return Decode(a, v)
 }
 
 func (d *decoder) Read(v []byte) (int, error) {
 	return d.reader.Read(v)
 }
 
+func (d *decoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *decoder) Reset(r io.Reader) error {
+	d.reader = r
+	return nil
+}
+
 func (d *decoder) Close() error {
 	return nil
 }
@@ -61,12 +72,17 @@ func (d *decoder) Close() error {
 func TestEncodeDecode(t *testing.T) {
 	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
 
-	encoded, err := Encode("mock", exp)
+	a, err := NewAlgorithm("mock", WithLevel(DefaultCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
 	if err != nil {
 		t.Error(err)
 	}
 
-	if got, err := Decode("mock", encoded); err != nil {
+	if got, err := Decode(a, encoded); err != nil {
 		t.Error(err)
 	} else if !bytes.Equal(exp, got) {
 		t.Error("decode response doesn't match what was encoded")
@@ -74,6 +90,37 @@ func TestEncodeDecode(t *testing.T) {
 }
 
 func TestNewEncoder(t *testing.T) {
+	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
+
+	var buf bytes.Buffer
+	e, err := NewEncoder("mock", &buf, WithLevel(DefaultCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.Write(exp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewDecoder("mock", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ioutil.ReadAll(d); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode response doesn't match what was encoded")
+	}
+
+	if _, err := NewEncoder("foo", &bytes.Buffer{}); err == nil {
+		t.Error("foo should not be a registered algorithm")
+	}
+
 	if _, err := NewDecoder("foo", bytes.NewBuffer([]byte(""))); err == nil {
 		t.Error("foo should not be a registered algorithm")
 	}
@@ -1,8 +1,137 @@
 package compress
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
+	// ErrNotRegistered no algorithm registered with the name
+	ErrNotRegistered = errors.New("algorithm not registered")
+
+	// ErrNoCandidates no candidate algorithms given
+	ErrNoCandidates = errors.New("no candidate algorithms")
+
 	// ErrUnsupportedOption unsupported option
 	ErrUnsupportedOption = errors.New("unsupported option")
+
+	// ErrEncodeUnsupported algorithm can only decode
+	ErrEncodeUnsupported = errors.New("encode unsupported")
+
+	// ErrConflictingOptions combination of options isn't consistent
+	ErrConflictingOptions = errors.New("conflicting options")
+
+	// ErrInvalidLevel compression level isn't valid for the algorithm
+	ErrInvalidLevel = errors.New("invalid level")
+
+	// ErrInvalidEndian endian isn't Little or Big
+	ErrInvalidEndian = errors.New("invalid endian")
+
+	// ErrEndianUnsupported algorithm doesn't use an endian
+	ErrEndianUnsupported = errors.New("endian unsupported")
+
+	// ErrInvalidLitWidth lit width must be in the range 2-8
+	ErrInvalidLitWidth = errors.New("invalid lit width")
+
+	// ErrLitWidthUnsupported algorithm doesn't use a lit width
+	ErrLitWidthUnsupported = errors.New("lit width unsupported")
+
+	// ErrInvalidWindowSize window size is out of range for the algorithm
+	ErrInvalidWindowSize = errors.New("invalid window size")
+
+	// ErrWindowSizeUnsupported algorithm has a fixed window size
+	ErrWindowSizeUnsupported = errors.New("window size unsupported")
+
+	// ErrTimeout operation took longer than the timeout
+	ErrTimeout = errors.New("timeout")
+
+	// ErrUnknownFormat format couldn't be identified
+	ErrUnknownFormat = errors.New("unknown format")
+
+	// ErrDecodedSizeExceeded decoded output is larger than the allowed max
+	ErrDecodedSizeExceeded = errors.New("decoded size exceeded")
+
+	// ErrInvalidLimit max decoded size is negative
+	ErrInvalidLimit = errors.New("invalid limit")
+
+	// ErrHeaderFieldUnsupported algorithm has no header or no such field in it
+	ErrHeaderFieldUnsupported = errors.New("header field unsupported")
+
+	// ErrDictionaryUnsupported algorithm doesn't support a preset dictionary
+	ErrDictionaryUnsupported = errors.New("dictionary unsupported")
+
+	// ErrChecksumToggleUnsupported algorithm always adds a checksum
+	ErrChecksumToggleUnsupported = errors.New("checksum toggle unsupported")
+
+	// ErrDictionaryMismatch dictionary doesn't match the one the stream was encoded with
+	ErrDictionaryMismatch = errors.New("dictionary mismatch")
+
+	// ErrConcurrencyUnsupported algorithm can't encode in parallel
+	ErrConcurrencyUnsupported = errors.New("concurrency unsupported")
+
+	// ErrInvalidBufferSize buffer size must be larger than zero
+	ErrInvalidBufferSize = errors.New("invalid buffer size")
+
+	// ErrInvalidBlockSize block size isn't valid for the algorithm
+	ErrInvalidBlockSize = errors.New("invalid block size")
+
+	// ErrBlockSizeUnsupported algorithm is stream only and doesn't use blocks
+	ErrBlockSizeUnsupported = errors.New("block size unsupported")
+
+	// ErrFlushUnsupported encoder can't flush without closing the stream
+	ErrFlushUnsupported = errors.New("flush unsupported")
+
+	// ErrInvalidMemoryLimit invalid max memory
+	ErrInvalidMemoryLimit = errors.New("invalid memory limit")
+
+	// ErrMemoryLimit stream needs more memory to decode than allowed
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+
+	// ErrFlushModeUnsupported algorithm only has the default flush
+	ErrFlushModeUnsupported = errors.New("flush mode unsupported")
+
+	// ErrAppendUnsupported algorithm can't decode concatenated streams
+	ErrAppendUnsupported = errors.New("append unsupported")
+
+	// ErrWriterAtUnsupported algorithm can't encode to an io.WriterAt
+	ErrWriterAtUnsupported = errors.New("writer at unsupported")
+
+	// ErrSeekUnsupported algorithm doesn't support random access
+	ErrSeekUnsupported = errors.New("seek unsupported")
+
+	// ErrDuplicateName archive has more than one file with the same name
+	ErrDuplicateName = errors.New("duplicate name")
+
+	// ErrChecksumMismatch checksum of the decoded data doesn't match the stream
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrInvalidEncoding unknown textual encoding
+	ErrInvalidEncoding = errors.New("invalid encoding")
+
+	// ErrDigestMismatch digest of the decoded data doesn't match the expected digest
+	ErrDigestMismatch = errors.New("digest mismatch")
+
+	// ErrChecksumUnsupported algorithm doesn't embed a checksum of the original data
+	ErrChecksumUnsupported = errors.New("checksum unsupported")
 )
+
+// ChecksumError checksum mismatch with the expected value stored in the stream and the actual value
+// computed from the decoded data.
+type ChecksumError struct {
+	Expected uint32
+	Actual   uint32
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: expected %08x got %08x", ErrChecksumMismatch, e.Expected, e.Actual)
+}
+
+// Cause for github.com/pkg/errors.
+func (e *ChecksumError) Cause() error {
+	return ErrChecksumMismatch
+}
+
+// Unwrap for errors.Is.
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
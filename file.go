@@ -0,0 +1,163 @@
+package compress
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// EncodeFile algorithm streaming from src to dst.
+// A partially written dst is removed on error.
+func EncodeFile(a Algorithm, src, dst string) error {
+	return copyFile(src, dst, bufferSize(a), func(r io.Reader, w io.Writer) error {
+		e, err := a.NewEncoder(w)
+		if err != nil {
+			return err
+		}
+
+		if err := copyTimeout(a, e, withProgress(a, r)); err != nil {
+			_ = e.Close()
+			return err
+		}
+
+		return e.Close()
+	})
+}
+
+// DecodeFile algorithm streaming from src to dst.
+// A partially written dst is removed on error.
+func DecodeFile(a Algorithm, src, dst string) error {
+	return copyFile(src, dst, bufferSize(a), func(r io.Reader, w io.Writer) error {
+		d, err := a.NewDecoder(withProgress(a, r))
+		if err != nil {
+			return err
+		}
+
+		if err := copyTimeout(a, w, d); err != nil {
+			_ = d.Close()
+			return err
+		}
+
+		return d.Close()
+	})
+}
+
+func copyFile(src, dst string, size int, fn func(r io.Reader, w io.Writer) error) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		_ = in.Close()
+		return err
+	}
+
+	w := bufio.NewWriterSize(out, size)
+	err = fn(bufio.NewReaderSize(in, size), w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	_ = in.Close()
+
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+// NewWriterForFile creates path and returns a writer encoding to it, using the algorithm matching the file extension.
+// Close finalizes the stream and closes the file. Unknown extensions return ErrUnknownFormat.
+func NewWriterForFile(path string, opts ...Option) (io.WriteCloser, error) {
+	name, err := DetectByExt(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := NewAlgorithm(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &fileWriter{file: out, buf: bufio.NewWriterSize(out, bufferSize(a))}
+	if w.encoder, err = a.NewEncoder(w.buf); err != nil {
+		_ = out.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// fileWriter encoding to a buffered file.
+type fileWriter struct {
+	encoder Encoder
+	buf     *bufio.Writer
+	file    *os.File
+}
+
+func (w *fileWriter) Write(v []byte) (int, error) {
+	return w.encoder.Write(v)
+}
+
+func (w *fileWriter) Close() error {
+	err := w.encoder.Close()
+	if err == nil {
+		err = w.buf.Flush()
+	}
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// NewReaderForFile opens path and returns a reader decoding it, using the algorithm detected by the magic bytes.
+// Close closes the decoder and the file. Uncompressed or unknown files return ErrUnknownFormat.
+func NewReaderForFile(path string, opts ...Option) (io.ReadCloser, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &fileReader{file: in}
+	name, replay, err := Detect(in)
+	if err == nil {
+		r.decoder, err = NewDecoder(name, replay, opts...)
+	}
+	if err != nil {
+		_ = in.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// fileReader decoding from a file.
+type fileReader struct {
+	decoder Decoder
+	file    *os.File
+}
+
+func (r *fileReader) Read(v []byte) (int, error) {
+	return r.decoder.Read(v)
+}
+
+func (r *fileReader) Close() error {
+	err := r.decoder.Close()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
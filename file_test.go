@@ -0,0 +1,142 @@
+package compress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeFileDecodeFile(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 11<<20; i++ {
+		fmt.Fprintf(&buf, "line %d: the quick brown fox jumps over the lazy dog\n", i)
+	}
+	exp := buf.Bytes()
+
+	src := filepath.Join(dir, "abc.txt")
+	if err := ioutil.WriteFile(src, exp, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := filepath.Join(dir, "abc.txt.gz")
+	if err := compress.EncodeFile(a, src, encoded); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := filepath.Join(dir, "abc.out.txt")
+	if err := compress.DecodeFile(a, encoded, decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ioutil.ReadFile(decoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded file doesn't match original")
+	}
+
+	invalid := filepath.Join(dir, "invalid.txt")
+	if err := compress.DecodeFile(a, src, invalid); err == nil {
+		t.Error("decoding a plain text file should fail")
+	}
+
+	if _, err := os.Stat(invalid); !os.IsNotExist(err) {
+		t.Error("partially written file should be removed on error")
+	}
+}
+
+func TestNewWriterForFile(t *testing.T) {
+	dir := t.TempDir()
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	path := filepath.Join(dir, "out.gz")
+	w, err := compress.NewWriterForFile(path, compress.WithLevel(compress.BestCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write(exp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ioutil.ReadAll(r); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("file contents don't match expected value")
+	}
+
+	if _, err := compress.NewWriterForFile(filepath.Join(dir, "out.foo")); err != compress.ErrUnknownFormat {
+		t.Errorf("expected %v got %v", compress.ErrUnknownFormat, err)
+	}
+}
+
+func TestNewReaderForFile(t *testing.T) {
+	dir := t.TempDir()
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	src := filepath.Join(dir, "abc.txt")
+	if err := ioutil.WriteFile(src, exp, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"gzip", "zstd"} {
+		a, err := compress.NewAlgorithm(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Without the extension to make sure the magic bytes are used.
+		dst := filepath.Join(dir, name)
+		if err := compress.EncodeFile(a, src, dst); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := compress.NewReaderForFile(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := ioutil.ReadAll(r); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("%s file contents don't match expected value", name)
+		}
+
+		if err := r.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := compress.NewReaderForFile(src); err != compress.ErrUnknownFormat {
+		t.Errorf("expected %v got %v", compress.ErrUnknownFormat, err)
+	}
+}
@@ -0,0 +1,269 @@
+package flate
+
+import (
+	"compress/flate"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.HuffmanOnly,
+	compress.DefaultCompression,
+	compress.NoCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
+type flateAlgorithm struct {
+	compress.Options
+
+	level     compress.Level
+	dict      []byte
+	flushMode compress.FlushMode
+}
+
+type flateEncoder struct {
+	writer    *flate.Writer
+	dst       io.Writer
+	flushMode compress.FlushMode
+
+	// start writer primed with the dictionary, after a full flush plain without it is used until Reset.
+	start *flate.Writer
+	plain *flate.Writer
+	level int
+	dict  bool
+}
+
+type flateDecoder struct {
+	reader io.ReadCloser
+	dict   []byte
+}
+
+func (a *flateAlgorithm) NewAlgorithm() compress.Algorithm {
+	return &flateAlgorithm{level: compress.DefaultCompression}
+}
+
+func (a *flateAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	c.dict = append([]byte(nil), a.dict...)
+	return &c
+}
+
+func (a *flateAlgorithm) Name() string {
+	return "flate"
+}
+
+func (a *flateAlgorithm) Ext() string {
+	return "deflate"
+}
+
+func (a *flateAlgorithm) String() string {
+	return fmt.Sprintf("flate(level=%d, dict=%d)", a.level, len(a.dict))
+}
+
+func (a *flateAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapDictionary | compress.CapFlush
+}
+
+func (a *flateAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+func (a *flateAlgorithm) DefaultLevel() compress.Level {
+	return compress.DefaultCompression
+}
+
+func (a *flateAlgorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm flate level %d", level)
+	}
+	a.level = level
+	return nil
+}
+
+func (a *flateAlgorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm flate")
+}
+
+// SetDeterministic is a no-op, the output of flate is always the same for the same input.
+func (a *flateAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *flateAlgorithm) SetDictionary(dict []byte) error {
+	a.dict = dict
+	return nil
+}
+
+func (a *flateAlgorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+// SetNoChecksum is a no-op, flate has no checksum.
+func (a *flateAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return nil
+}
+
+func (a *flateAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	switch mode {
+	case compress.SyncFlush, compress.NoFlush, compress.FullFlush:
+		a.flushMode = mode
+		return nil
+	}
+	return errors.Wrapf(compress.ErrUnsupportedOption, "algorithm flate flush mode %d", mode)
+}
+
+func (a *flateAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm flate")
+}
+
+func (a *flateAlgorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm flate")
+}
+
+// SetMaxMemory is a no-op, the window is 32KB.
+func (a *flateAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm flate max memory %d", n)
+	}
+	return nil
+}
+
+// Validate the dictionary isn't used without compression, flate ignores it for NoCompression and HuffmanOnly.
+func (a *flateAlgorithm) Validate() error {
+	if len(a.dict) > 0 && (a.level == compress.NoCompression || a.level == compress.HuffmanOnly) {
+		return errors.Wrapf(compress.ErrConflictingOptions, "algorithm flate dictionary is ignored at level %d", a.level)
+	}
+	return nil
+}
+
+func (a *flateAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	e := &flateEncoder{dst: w, flushMode: a.flushMode, level: int(a.level), dict: len(a.dict) > 0}
+	var err error
+	if e.writer, err = flate.NewWriterDict(w, int(a.level), a.dict); err != nil {
+		return nil, err
+	}
+	e.start = e.writer
+	return e, nil
+}
+
+func (a *flateAlgorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+func (e *flateEncoder) Write(v []byte) (int, error) {
+	return e.writer.Write(v)
+}
+
+func (e *flateEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
+}
+
+func (e *flateEncoder) Reset(w io.Writer) error {
+	e.dst = w
+	e.writer = e.start
+	e.writer.Reset(w)
+	return nil
+}
+
+func (e *flateEncoder) Flush() error {
+	switch e.flushMode {
+	case compress.NoFlush:
+		return nil
+	case compress.FullFlush:
+		// The stream continues without a final block, a new compressor has no references to the data before.
+		if err := e.writer.Flush(); err != nil {
+			return err
+		}
+		if !e.dict {
+			e.writer.Reset(e.dst)
+			return nil
+		}
+
+		// Reset primes the dictionary again, which the decoder doesn't have at this point of the stream.
+		if e.plain == nil {
+			var err error
+			if e.plain, err = flate.NewWriter(e.dst, e.level); err != nil {
+				return err
+			}
+		} else {
+			e.plain.Reset(e.dst)
+		}
+		e.writer = e.plain
+		return nil
+	}
+	return e.writer.Flush()
+}
+
+func (e *flateEncoder) Close() error {
+	return e.writer.Close()
+}
+
+func (a *flateAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	return &flateDecoder{
+		reader: flate.NewReaderDict(r, a.dict),
+		dict:   a.dict,
+	}, nil
+}
+
+func (a *flateAlgorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *flateDecoder) Read(v []byte) (int, error) {
+	return d.reader.Read(v)
+}
+
+func (d *flateDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *flateDecoder) Reset(r io.Reader) error {
+	return d.reader.(flate.Resetter).Reset(r, d.dict)
+}
+
+func (d *flateDecoder) Close() error {
+	return d.reader.Close()
+}
+
+func init() {
+	compress.Register("flate", &flateAlgorithm{})
+}
@@ -0,0 +1,139 @@
+package flate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+	_ "github.com/mickep76/compress/zlib"
+)
+
+func TestNotZlib(t *testing.T) {
+	a, err := compress.NewAlgorithm("flate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode([]byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := compress.NewAlgorithm("zlib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := z.Decode(encoded); err == nil {
+		t.Error("raw deflate should not be decodable by zlib")
+	}
+}
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.DEFLATE"); err != nil {
+		t.Error(err)
+	} else if name != "flate" {
+		t.Errorf("expected flate got %s", name)
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	dict := []byte(`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 0, "name": "", "email": "", "verified": false, "created": "2018-06-01T12:00:00Z"}}`)
+	exp := []byte(`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 42, "name": "abc", "email": "abc@example.com", "verified": true, "created": "2018-06-01T12:00:00Z"}}`)
+
+	a, err := compress.NewAlgorithm("flate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := compress.NewAlgorithm("flate", compress.WithDictionary(dict))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := d.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(encoded) >= len(plain) {
+		t.Errorf("dictionary encoded (%d bytes) should be smaller than without dictionary (%d bytes)", len(encoded), len(plain))
+	}
+
+	if got, err := d.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded output doesn't match original")
+	}
+
+	if got, err := a.Decode(encoded); err == nil && bytes.Equal(exp, got) {
+		t.Error("decoding without the dictionary should fail")
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.HuffmanOnly, compress.NoCompression, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("flate", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{-3, 10} {
+		if _, err := compress.NewAlgorithm("flate", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
+
+func TestFullFlushDictionary(t *testing.T) {
+	dict := []byte(`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 0, "name": "", "email": ""}}`)
+	parts := []string{
+		`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 1, "name": "abc", "email": ""}}`,
+		`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 2, "name": "def", "email": ""}}`,
+		`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 3, "name": "ghi", "email": ""}}`,
+	}
+
+	a, err := compress.NewAlgorithm("flate", compress.WithDictionary(dict), compress.WithFlushMode(compress.FullFlush))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	e, err := a.NewEncoder(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reset after a full flush starts the next stream with the dictionary again.
+	for i := 0; i < 2; i++ {
+		buf.Reset()
+		if err := e.Reset(buf); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range parts {
+			if _, err := e.Write([]byte(p)); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := a.Decode(buf.Bytes()); err != nil {
+			t.Error(err)
+		} else if exp := strings.Join(parts, ""); string(got) != exp {
+			t.Errorf("expected %q got %q", exp, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package flate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
+
+	for _, level := range []compress.Level{compress.NoCompression, compress.BestSpeed, compress.BestCompression, compress.DefaultCompression, compress.HuffmanOnly} {
+		a, err := compress.NewAlgorithm("flate", compress.WithLevel(level))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(exp)
+		if err != nil {
+			t.Error(errors.Wrapf(err, "test encode level %d", level))
+		}
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Error(errors.Wrapf(err, "test decode level %d", level))
+		} else if !bytes.Equal(exp, got) {
+			t.Error(errors.Errorf("test decode level %d doesn't match expected value", level))
+		}
+	}
+}
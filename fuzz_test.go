@@ -0,0 +1,79 @@
+package compress_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// FuzzDecode decode arbitrary input with every registered algorithm, it may return an error but never panic.
+// The corpus is seeded with the encoded output of each algorithm, testdata/fuzz.bz2 since bzip2 is decode only.
+// Run with: go test . -run '^$' -fuzz FuzzDecode
+func FuzzDecode(f *testing.F) {
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 10)
+	for _, name := range algorithms() {
+		a, err := compress.NewAlgorithm(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		encoded, err := a.Encode(v)
+		if errors.Cause(err) == compress.ErrEncodeUnsupported {
+			continue
+		}
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(encoded)
+	}
+
+	encoded, err := ioutil.ReadFile("testdata/fuzz.bz2")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(encoded)
+
+	f.Fuzz(func(t *testing.T, encoded []byte) {
+		for _, name := range algorithms() {
+			a, err := compress.NewAlgorithm(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = a.Decode(encoded)
+		}
+	})
+}
+
+// FuzzRoundTrip encode arbitrary input with every registered algorithm that can encode and decode it back.
+// Run with: go test . -run '^$' -fuzz FuzzRoundTrip
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0})
+	f.Add([]byte("a"))
+	f.Add(bytes.Repeat([]byte{0}, 4096))
+	f.Add(bytes.Repeat([]byte("abc123\ndef456\n"), 100))
+
+	f.Fuzz(func(t *testing.T, v []byte) {
+		for _, name := range algorithms() {
+			a, err := compress.NewAlgorithm(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := a.Encode(v)
+			if errors.Cause(err) == compress.ErrEncodeUnsupported {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if got, err := a.Decode(encoded); err != nil {
+				t.Errorf("%s: %v", name, err)
+			} else if !bytes.Equal(v, got) {
+				t.Errorf("%s: decode doesn't match the input", name)
+			}
+		}
+	})
+}
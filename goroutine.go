@@ -0,0 +1,54 @@
+package compress
+
+import (
+	"sync"
+)
+
+var (
+	// slots semaphore for helper goroutines, nil is unlimited.
+	slots     chan struct{}
+	slotsLock sync.RWMutex
+)
+
+// SetMaxGoroutines limit the goroutines of helpers such as EncodeReader and DecodeChunkedParallel to n,
+// n <= 0 is unlimited. When the limit is reached EncodeReader blocks until a goroutine finishes, so readers
+// must be read to the end or closed, and DecodeChunkedParallel uses fewer workers.
+func SetMaxGoroutines(n int) {
+	slotsLock.Lock()
+	defer slotsLock.Unlock()
+	if n <= 0 {
+		slots = nil
+		return
+	}
+	slots = make(chan struct{}, n)
+}
+
+// acquire a slot for a goroutine blocking until one is free, the returned function releases it.
+func acquire() func() {
+	slotsLock.RLock()
+	s := slots
+	slotsLock.RUnlock()
+	if s == nil {
+		return func() {}
+	}
+
+	s <- struct{}{}
+	return func() { <-s }
+}
+
+// tryAcquire a slot for a goroutine without blocking.
+func tryAcquire() (func(), bool) {
+	slotsLock.RLock()
+	s := slots
+	slotsLock.RUnlock()
+	if s == nil {
+		return func() {}, true
+	}
+
+	select {
+	case s <- struct{}{}:
+		return func() { <-s }, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,15 @@
+package gzip
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/mickep76/compress/internal/trailer"
+)
+
+// newTrailerReader keeps the last 8 bytes read, which is the gzip trailer (CRC32 and ISIZE) when a member ends.
+func newTrailerReader(r io.Reader) *trailer.Reader {
+	return trailer.NewReader(r, 8, func(v []byte) uint32 {
+		return binary.LittleEndian.Uint32(v[:4])
+	})
+}
@@ -2,56 +2,216 @@ package gzip
 
 import (
 	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/mickep76/compress"
+	"github.com/mickep76/compress/internal/trailer"
 )
 
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.HuffmanOnly,
+	compress.DefaultCompression,
+	compress.NoCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
 type gzipAlgorithm struct {
-	level compress.Level
+	compress.Options
+
+	level     compress.Level
+	name      string
+	comment   string
+	modTime   time.Time
+	os        byte
+	extra     []byte
+	verify    bool
+	flushMode compress.FlushMode
+
+	// deterministic ignores the mod time and OS.
+	deterministic bool
+
+	// trustSize preallocates the decoded size from ISIZE in the trailer.
+	trustSize bool
 }
 
 type gzipEncoder struct {
-	writer *gzip.Writer
+	writer    writer
+	header    gzip.Header
+	flushMode compress.FlushMode
 }
 
 type gzipDecoder struct {
-	reader *gzip.Reader
+	reader      *gzip.Reader
+	multistream bool
+
+	// verify members are read one at a time to compute the CRC32 for each.
+	verify  bool
+	source  io.Reader
+	trailer *trailer.Reader
+	crc     uint32
 }
 
 func (a *gzipAlgorithm) NewAlgorithm() compress.Algorithm {
-	return &gzipAlgorithm{}
+	// OS unknown, same as compress/gzip.
+	return &gzipAlgorithm{level: compress.DefaultCompression, os: 255}
+}
+
+func (a *gzipAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	c.extra = append([]byte(nil), a.extra...)
+	return &c
+}
+
+func (a *gzipAlgorithm) Name() string {
+	return "gzip"
 }
 
 func (a *gzipAlgorithm) Ext() string {
 	return "gz"
 }
 
+func (a *gzipAlgorithm) String() string {
+	return fmt.Sprintf("gzip(level=%d)", a.level)
+}
+
+func (a *gzipAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapFlush | compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *gzipAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+func (a *gzipAlgorithm) DefaultLevel() compress.Level {
+	return compress.DefaultCompression
+}
+
 func (a *gzipAlgorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm gzip level %d", level)
+	}
 	a.level = level
 	return nil
 }
 
 func (a *gzipAlgorithm) SetEndian(endian compress.Endian) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm gzip")
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm gzip")
 }
 
 func (a *gzipAlgorithm) SetLitWidth(width int) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm gzip")
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm gzip")
+}
+
+func (a *gzipAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm gzip")
+}
+
+func (a *gzipAlgorithm) SetName(name string) error {
+	a.name = name
+	return nil
+}
+
+func (a *gzipAlgorithm) SetComment(comment string) error {
+	a.comment = comment
+	return nil
+}
+
+func (a *gzipAlgorithm) SetModTime(modTime time.Time) error {
+	a.modTime = modTime
+	return nil
+}
+
+func (a *gzipAlgorithm) SetOS(os byte) error {
+	a.os = os
+	return nil
+}
+
+func (a *gzipAlgorithm) SetExtra(extra []byte) error {
+	a.extra = extra
+	return nil
+}
+
+func (a *gzipAlgorithm) SetDeterministic(deterministic bool) error {
+	a.deterministic = deterministic
+	return nil
+}
+
+func (a *gzipAlgorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm gzip")
+}
+
+func (a *gzipAlgorithm) SetChecksumVerify(verify bool) error {
+	a.verify = verify
+	return nil
+}
+
+func (a *gzipAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return errors.Wrap(compress.ErrChecksumToggleUnsupported, "algorithm gzip")
+}
+
+func (a *gzipAlgorithm) SetTrustSize(trust bool) error {
+	a.trustSize = trust
+	return nil
+}
+
+func (a *gzipAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	switch mode {
+	case compress.SyncFlush, compress.NoFlush, compress.FullFlush:
+		a.flushMode = mode
+		return nil
+	}
+	return errors.Wrapf(compress.ErrUnsupportedOption, "algorithm gzip flush mode %d", mode)
+}
+
+func (a *gzipAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm gzip")
+}
+
+func (a *gzipAlgorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm gzip")
+}
+
+// SetMaxMemory is a no-op, the window is 32KB.
+func (a *gzipAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm gzip max memory %d", n)
+	}
+	return nil
+}
+
+func (a *gzipAlgorithm) Validate() error {
+	return nil
 }
 
 func (a *gzipAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
-	e := &gzipEncoder{}
-	if a.level == 0 {
-		e.writer = gzip.NewWriter(w)
-	} else {
-		var err error
-		if e.writer, err = gzip.NewWriterLevel(w, int(a.level)); err != nil {
+	e := &gzipEncoder{flushMode: a.flushMode}
+	e.header = gzip.Header{Name: a.name, Comment: a.comment, ModTime: a.modTime, OS: a.os, Extra: a.extra}
+	if a.deterministic {
+		e.header.ModTime = time.Time{}
+		e.header.OS = 255
+	}
+
+	var err error
+	if a.flushMode == compress.FullFlush {
+		if e.writer, err = newFullFlushWriter(w, int(a.level), e.header); err != nil {
 			return nil, err
 		}
+		return e, nil
+	}
+	gw, err := gzip.NewWriterLevel(w, int(a.level))
+	if err != nil {
+		return nil, err
 	}
+	gw.Header = e.header
+	e.writer = gw
 	return e, nil
 }
 
@@ -63,25 +223,120 @@ func (e *gzipEncoder) Write(v []byte) (int, error) {
 	return e.writer.Write(v)
 }
 
+func (e *gzipEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
+}
+
+func (e *gzipEncoder) Reset(w io.Writer) error {
+	e.writer.Reset(w)
+	if gw, ok := e.writer.(*gzip.Writer); ok {
+		gw.Header = e.header
+	}
+	return nil
+}
+
+func (e *gzipEncoder) Flush() error {
+	if e.flushMode == compress.NoFlush {
+		return nil
+	}
+	return e.writer.Flush()
+}
+
 func (e *gzipEncoder) Close() error {
 	return e.writer.Close()
 }
 
 func (a *gzipAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
-	d := &gzipDecoder{}
+	d := &gzipDecoder{multistream: true, verify: a.verify}
+	if d.verify {
+		d.source = r
+		d.trailer = newTrailerReader(r)
+		r = d.trailer
+	}
+
 	var err error
 	if d.reader, err = gzip.NewReader(r); err != nil {
 		return nil, err
 	}
+	// Decode all members of concatenated streams, i.e. cat a.gz b.gz.
+	d.reader.Multistream(!d.verify)
 	return d, nil
 }
 
+// DecodedSize ISIZE in the trailer when trust size is set, it's the size modulo 2^32 of the last member.
+func (a *gzipAlgorithm) DecodedSize(v []byte) (int64, bool) {
+	if !a.trustSize || len(v) < 18 {
+		return 0, false
+	}
+	return int64(binary.LittleEndian.Uint32(v[len(v)-4:])), true
+}
+
 func (a *gzipAlgorithm) Decode(v []byte) ([]byte, error) {
 	return compress.Decode(a, v)
 }
 
 func (d *gzipDecoder) Read(v []byte) (int, error) {
-	return d.reader.Read(v)
+	n, err := d.reader.Read(v)
+	if !d.verify {
+		return n, err
+	}
+
+	d.crc = crc32.Update(d.crc, crc32.IEEETable, v[:n])
+	switch {
+	case err == gzip.ErrChecksum:
+		return n, &compress.ChecksumError{Expected: d.trailer.Checksum(), Actual: d.crc}
+	case err == io.EOF && d.multistream:
+		d.crc = 0
+		if err := d.reader.Reset(d.trailer); err != nil {
+			return n, err
+		}
+		d.reader.Multistream(false)
+		return n, nil
+	}
+	return n, err
+}
+
+func (d *gzipDecoder) WriteTo(w io.Writer) (int64, error) {
+	if d.verify {
+		// Go through Read which checks the checksum.
+		return io.Copy(w, struct{ io.Reader }{d})
+	}
+	return io.Copy(w, d.reader)
+}
+
+func (d *gzipDecoder) Header() *compress.Header {
+	return &compress.Header{
+		Name:    d.reader.Name,
+		Comment: d.reader.Comment,
+		ModTime: d.reader.ModTime,
+		OS:      d.reader.OS,
+	}
+}
+
+func (d *gzipDecoder) Multistream(ok bool) {
+	d.multistream = ok
+	if !d.verify {
+		d.reader.Multistream(ok)
+	}
+}
+
+func (d *gzipDecoder) Reset(r io.Reader) error {
+	d.multistream = true
+	if d.verify {
+		// Keep the buffered reader when continuing with the next member of the same source.
+		if r != d.source {
+			d.source = r
+			d.trailer = newTrailerReader(r)
+		}
+		r = d.trailer
+		d.crc = 0
+	}
+
+	if err := d.reader.Reset(r); err != nil {
+		return err
+	}
+	d.reader.Multistream(!d.verify)
+	return nil
 }
 
 func (d *gzipDecoder) Close() error {
@@ -2,9 +2,20 @@ package gzip
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/mickep76/compress"
+	_ "github.com/mickep76/compress/zlib"
+	_ "github.com/mickep76/compress/zstd"
 )
 
 func TestNewDecoder(t *testing.T) {
@@ -12,3 +23,725 @@ func TestNewDecoder(t *testing.T) {
 		t.Error("this should have generated a EOF error")
 	}
 }
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.GZ"); err != nil {
+		t.Error(err)
+	} else if name != "gzip" {
+		t.Errorf("expected gzip got %s", name)
+	}
+}
+
+func TestName(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Name() != "gzip" {
+		t.Errorf("expected gzip got %s", a.Name())
+	}
+}
+
+func TestString(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithLevel(compress.BestCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := a.String(); s != "gzip(level=9)" {
+		t.Errorf("expected gzip(level=9) got %s", s)
+	}
+
+	z, err := compress.NewAlgorithm("zlib", compress.WithDictionary([]byte("abc123")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := fmt.Sprint(z); s != "zlib(level=-1, dict=6)" {
+		t.Errorf("expected zlib(level=-1, dict=6) got %s", s)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	modTime := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	a, err := compress.NewAlgorithm("gzip", compress.WithName("abc.txt"), compress.WithComment("abc"), compress.WithModTime(modTime))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode([]byte("abc123\ndef456\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Header.Name != "abc.txt" {
+		t.Errorf("expected name abc.txt got %s", r.Header.Name)
+	}
+
+	if r.Header.Comment != "abc" {
+		t.Errorf("expected comment abc got %s", r.Header.Comment)
+	}
+
+	if !r.Header.ModTime.Equal(modTime) {
+		t.Errorf("expected mod time %s got %s", modTime, r.Header.ModTime)
+	}
+}
+
+func TestHeaderOSExtra(t *testing.T) {
+	extra := []byte{'a', 'b', 3, 0, 'a', 'b', 'c'}
+	a, err := compress.NewAlgorithm("gzip", compress.WithOS(3), compress.WithExtra(extra))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode([]byte("abc123\ndef456\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Header.OS != 3 {
+		t.Errorf("expected os 3 got %d", r.Header.OS)
+	}
+
+	if !bytes.Equal(extra, r.Header.Extra) {
+		t.Errorf("expected extra %q got %q", extra, r.Header.Extra)
+	}
+
+	if _, err := compress.NewAlgorithm("zstd", compress.WithOS(3)); errors.Cause(err) != compress.ErrHeaderFieldUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrHeaderFieldUnsupported, err)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	v := []byte("abc123\ndef456\n")
+	modTimes := []time.Time{time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC), time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)}
+
+	for _, deterministic := range []bool{true, false} {
+		var encoded [][]byte
+		for i, modTime := range modTimes {
+			a, err := compress.NewAlgorithm("gzip", compress.WithModTime(modTime), compress.WithOS(byte(i)), compress.WithDeterministic(deterministic))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := a.Encode(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded = append(encoded, v)
+		}
+
+		if bytes.Equal(encoded[0], encoded[1]) != deterministic {
+			t.Errorf("deterministic %t expected equal output to be %t", deterministic, deterministic)
+		}
+	}
+}
+
+func TestEncoderReset(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithName("abc.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	e, err := a.NewEncoder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range [][]byte{[]byte("abc123\n"), []byte("def456\n")} {
+		buf.Reset()
+		if err := e.Reset(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := e.Write(exp); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		got, h, err := compress.DecodeWithHeader(a, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(exp, got) {
+			t.Errorf("expected %q got %q", exp, got)
+		}
+
+		if h.Name != "abc.txt" {
+			t.Errorf("header name should survive reset, got %q", h.Name)
+		}
+	}
+}
+
+var benchPayload = bytes.Repeat([]byte("abc123\ndef456\n"), 64)
+
+func BenchmarkNewEncoder(b *testing.B) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e, err := a.NewEncoder(ioutil.Discard)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := e.Write(benchPayload); err != nil {
+			b.Fatal(err)
+		}
+
+		if err := e.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncoderReset(b *testing.B) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	e, err := a.NewEncoder(ioutil.Discard)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := e.Reset(ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := e.Write(benchPayload); err != nil {
+			b.Fatal(err)
+		}
+
+		if err := e.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecoderReset(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exps := [][]byte{[]byte("abc123\n"), []byte("def456\n"), []byte("ghi789\n")}
+	encoded := [][]byte{}
+	for _, exp := range exps {
+		v, err := a.Encode(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, v)
+	}
+
+	d, err := a.NewDecoder(bytes.NewReader(encoded[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, exp := range exps {
+		if err := d.Reset(bytes.NewReader(encoded[i])); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := ioutil.ReadAll(d); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("expected %q got %q", exp, got)
+		}
+	}
+
+	if err := d.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecodeMultistream(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exp, concat []byte
+	for _, v := range [][]byte{[]byte("abc123\n"), []byte("def456\n"), []byte("ghi789\n")} {
+		encoded, err := a.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp = append(exp, v...)
+		concat = append(concat, encoded...)
+	}
+
+	if got, err := a.Decode(concat); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Errorf("expected %q got %q", exp, got)
+	}
+}
+
+func TestChecksumVerify(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithChecksumVerify(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []byte("abc123\ndef456\n")
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(append(append([]byte{}, encoded...), encoded...)); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(append(append([]byte{}, exp...), exp...), got) {
+		t.Errorf("unexpected multistream output %q", got)
+	}
+
+	encoded[len(encoded)-8] ^= 0xff
+	_, err = a.Decode(encoded)
+	if errors.Cause(err) != compress.ErrChecksumMismatch {
+		t.Fatalf("expected checksum mismatch got: %v", err)
+	}
+
+	cerr, ok := err.(*compress.ChecksumError)
+	if !ok {
+		t.Fatalf("expected *compress.ChecksumError got %T", err)
+	}
+
+	if cerr.Actual != crc32.ChecksumIEEE(exp) || cerr.Expected != cerr.Actual^0xff {
+		t.Errorf("unexpected checksum values: %s", cerr)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, level := range a.ValidLevels() {
+		if _, err := compress.NewAlgorithm("gzip", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{-3, 10} {
+		if _, err := compress.NewAlgorithm("gzip", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+
+	// No compression should store the payload rather than fall back to the default level.
+	a, err = compress.NewAlgorithm("gzip", compress.WithLevel(compress.NoCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := bytes.Repeat([]byte("abc"), 1000)
+	encoded, err := a.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(encoded) <= len(v) {
+		t.Errorf("expected stored output larger than %d bytes got %d", len(v), len(encoded))
+	}
+}
+
+func TestBufferSize(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		if _, err := compress.NewAlgorithm("gzip", compress.WithBufferSize(n)); errors.Cause(err) != compress.ErrInvalidBufferSize {
+			t.Errorf("buffer size %d expected %v got %v", n, compress.ErrInvalidBufferSize, err)
+		}
+	}
+
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+	for _, n := range []int{1, 7, 1 << 20} {
+		a, err := compress.NewAlgorithm("gzip", compress.WithBufferSize(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("buffer size %d decode doesn't match expected value", n)
+		}
+	}
+}
+
+func BenchmarkDecodeBufferSize(b *testing.B) {
+	enc, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	payload := bytes.Repeat([]byte("abc123\ndef456\n"), 4<<20/14)
+	encoded, err := enc.Encode(payload)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, n := range []int{32 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("%dKB", n>>10), func(b *testing.B) {
+			a, err := compress.NewAlgorithm("gzip", compress.WithBufferSize(n))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.SetBytes(int64(len(payload)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := a.Decode(encoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoderReadFrom(t *testing.T) {
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 10000)
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exp bytes.Buffer
+	e, err := a.NewEncoder(&exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < len(v); i += 1000 {
+		if _, err := e.Write(v[i : i+1000]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+	if e, err = a.NewEncoder(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := e.ReadFrom(bytes.NewReader(v)); err != nil {
+		t.Fatal(err)
+	} else if n != int64(len(v)) {
+		t.Errorf("expected %d bytes read got %d", len(v), n)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(exp.Bytes(), got.Bytes()) {
+		t.Error("read from output doesn't match repeated writes")
+	}
+}
+
+func TestDecoderWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 4<<20; i++ {
+		fmt.Fprintf(&buf, "line %d: the quick brown fox jumps over the lazy dog\n", i)
+	}
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := a.NewDecoder(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exp bytes.Buffer
+	v := make([]byte, 4096)
+	for {
+		n, err := d.Read(v)
+		exp.Write(v[:n])
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := d.Reset(bytes.NewReader(encoded)); err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+	if n, err := d.WriteTo(&got); err != nil {
+		t.Fatal(err)
+	} else if n != int64(exp.Len()) {
+		t.Errorf("expected %d bytes written got %d", exp.Len(), n)
+	}
+
+	if !bytes.Equal(exp.Bytes(), got.Bytes()) {
+		t.Error("write to output doesn't match read loop output")
+	}
+}
+
+func TestEncoderFlush(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	e, err := a.NewEncoder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.Write([]byte("abc123\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The flushed output is decodable before the stream is closed.
+	r, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partial := make([]byte, 7)
+	if _, err := io.ReadFull(r, partial); err != nil {
+		t.Fatal(err)
+	} else if string(partial) != "abc123\n" {
+		t.Errorf("expected abc123 got %q", partial)
+	}
+
+	if _, err := e.Write([]byte("def456\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(buf.Bytes()); err != nil {
+		t.Error(err)
+	} else if string(got) != "abc123\ndef456\n" {
+		t.Errorf("expected abc123 def456 got %q", got)
+	}
+}
+
+func TestSetLitWidthUnsupported(t *testing.T) {
+	if _, err := compress.NewAlgorithm("gzip", compress.WithLitWidth(8)); errors.Cause(err) != compress.ErrLitWidthUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrLitWidthUnsupported, err)
+	}
+}
+
+func TestNoChecksumUnsupported(t *testing.T) {
+	if _, err := compress.NewAlgorithm("gzip", compress.WithNoChecksum(true)); errors.Cause(err) != compress.ErrChecksumToggleUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrChecksumToggleUnsupported, err)
+	}
+}
+
+func TestWindowSizeUnsupported(t *testing.T) {
+	if _, err := compress.NewAlgorithm("gzip", compress.WithWindowSize(1<<15)); errors.Cause(err) != compress.ErrWindowSizeUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrWindowSizeUnsupported, err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	a, err := compress.NewAlgorithm("gzip", compress.WithLevel(compress.BestCompression))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := a.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := a.Clone()
+	if err := c.SetLevel(compress.NoCompression); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Encode(v); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("changing the level of the clone shouldn't change the original")
+	}
+
+	if got, err := c.Encode(v); err != nil {
+		t.Fatal(err)
+	} else if bytes.Equal(exp, got) {
+		t.Error("clone should use its own level")
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := a.Encode(benchPayload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestTrustSize(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithTrustSize(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&buf, "line %d\n", i)
+	}
+	exp := buf.Bytes()
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	// ISIZE of the last member is smaller than the concatenated stream.
+	tail, err := a.Encode([]byte("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := a.Decode(append(append([]byte(nil), encoded...), tail...)); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(append(append([]byte(nil), exp...), "abc"...), got) {
+		t.Error("decode of concatenated members doesn't match expected value")
+	}
+
+	// A bogus ISIZE is only used to preallocate, decoding reports the size mismatch as without the option.
+	bogus := append([]byte(nil), tail...)
+	binary.LittleEndian.PutUint32(bogus[len(bogus)-4:], 0xffffffff)
+	if _, err := a.Decode(bogus); err != gzip.ErrChecksum {
+		t.Errorf("expected %v got %v", gzip.ErrChecksum, err)
+	}
+
+	if _, err := compress.NewAlgorithm("zlib", compress.WithTrustSize(true)); errors.Cause(err) != compress.ErrUnsupportedOption {
+		t.Errorf("expected %v got %v", compress.ErrUnsupportedOption, err)
+	}
+}
+
+func TestFullFlushHeader(t *testing.T) {
+	modTime := time.Unix(1500000000, 0)
+	opts := []compress.Option{
+		compress.WithName("abc.txt"),
+		compress.WithComment("comment"),
+		compress.WithModTime(modTime),
+		compress.WithExtra([]byte("extra")),
+		compress.WithLevel(compress.BestCompression),
+	}
+	a, err := compress.NewAlgorithm("gzip", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := a.Encode([]byte("abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := compress.NewAlgorithm("gzip", append(opts, compress.WithFlushMode(compress.FullFlush))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.Encode([]byte("abcdef")); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("full flush encode without a flush doesn't match compress/gzip")
+	}
+}
+
+func TestFullFlushDictionary(t *testing.T) {
+	if _, err := compress.NewAlgorithm("gzip", compress.WithDictionary([]byte("abc")), compress.WithFlushMode(compress.FullFlush)); errors.Cause(err) != compress.ErrDictionaryUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrDictionaryUnsupported, err)
+	}
+
+	a, err := compress.NewAlgorithm("gzip", compress.WithFlushMode(compress.FullFlush))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	e, err := a.NewEncoder(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"abc", "def", "ghi"} {
+		if _, err := e.Write([]byte(p)); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(buf.Bytes()); err != nil {
+		t.Error(err)
+	} else if string(got) != "abcdefghi" {
+		t.Errorf("expected abcdefghi got %q", got)
+	}
+}
+
+func TestDefaultLevel(t *testing.T) {
+	if l := compress.MustNewAlgorithm("gzip").DefaultLevel(); l != compress.DefaultCompression {
+		t.Errorf("expected %d got %d", compress.DefaultCompression, l)
+	}
+}
@@ -12,12 +12,17 @@ import (
 func TestEncodeDecode(t *testing.T) {
 	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
 
-	encoded, err := compress.Encode("gzip", exp)
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
 	if err != nil {
 		t.Error(errors.Wrap(err, "test encode"))
 	}
 
-	if got, err := compress.Decode("gzip", encoded); err != nil {
+	if got, err := a.Decode(encoded); err != nil {
 		t.Error(errors.Wrap(err, "test decode"))
 	} else if !bytes.Equal(exp, got) {
 		t.Error(errors.Errorf("test decode doesn't match expected value"))
@@ -0,0 +1,138 @@
+package gzip
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// writer implemented by gzip.Writer and fullFlushWriter.
+type writer interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// fullFlushWriter writes the gzip header and trailer itself around a flate writer, so the compressor can be
+// reset on Flush which gzip.Writer doesn't support.
+type fullFlushWriter struct {
+	dst         io.Writer
+	level       int
+	header      gzip.Header
+	writer      *flate.Writer
+	crc         uint32
+	size        uint32
+	wroteHeader bool
+}
+
+func newFullFlushWriter(w io.Writer, level int, header gzip.Header) (*fullFlushWriter, error) {
+	fw, err := flate.NewWriter(w, level)
+	if err != nil {
+		return nil, err
+	}
+	return &fullFlushWriter{dst: w, level: level, header: header, writer: fw}, nil
+}
+
+// latin1 header strings are ISO 8859-1 and NUL terminated, same as compress/gzip.
+func latin1(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s)+1)
+	for _, r := range s {
+		if r == 0 || r > 0xff {
+			return nil, errors.New("gzip.Write: non-Latin-1 header string")
+		}
+		b = append(b, byte(r))
+	}
+	return append(b, 0), nil
+}
+
+// writeHeader same as compress/gzip.
+func (z *fullFlushWriter) writeHeader() error {
+	z.wroteHeader = true
+	h := []byte{0x1f, 0x8b, 8, 0, 0, 0, 0, 0, 0, z.header.OS}
+	if z.header.Extra != nil {
+		h[3] |= 0x04
+	}
+	if z.header.Name != "" {
+		h[3] |= 0x08
+	}
+	if z.header.Comment != "" {
+		h[3] |= 0x10
+	}
+	if z.header.ModTime.After(time.Unix(0, 0)) {
+		binary.LittleEndian.PutUint32(h[4:8], uint32(z.header.ModTime.Unix()))
+	}
+	switch z.level {
+	case gzip.BestCompression:
+		h[8] = 2
+	case gzip.BestSpeed:
+		h[8] = 4
+	}
+	if z.header.Extra != nil {
+		h = binary.LittleEndian.AppendUint16(h, uint16(len(z.header.Extra)))
+		h = append(h, z.header.Extra...)
+	}
+	for _, s := range []string{z.header.Name, z.header.Comment} {
+		if s == "" {
+			continue
+		}
+		b, err := latin1(s)
+		if err != nil {
+			return err
+		}
+		h = append(h, b...)
+	}
+	_, err := z.dst.Write(h)
+	return err
+}
+
+func (z *fullFlushWriter) Write(v []byte) (int, error) {
+	if !z.wroteHeader {
+		if err := z.writeHeader(); err != nil {
+			return 0, err
+		}
+	}
+	z.crc = crc32.Update(z.crc, crc32.IEEETable, v)
+	z.size += uint32(len(v))
+	return z.writer.Write(v)
+}
+
+// Flush sync flush and reset the compressor, decoding with flate can start at the offset after it.
+func (z *fullFlushWriter) Flush() error {
+	if !z.wroteHeader {
+		if err := z.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if err := z.writer.Flush(); err != nil {
+		return err
+	}
+	z.writer.Reset(z.dst)
+	return nil
+}
+
+func (z *fullFlushWriter) Reset(w io.Writer) {
+	z.dst = w
+	z.writer.Reset(w)
+	z.crc = 0
+	z.size = 0
+	z.wroteHeader = false
+}
+
+func (z *fullFlushWriter) Close() error {
+	if !z.wroteHeader {
+		if err := z.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if err := z.writer.Close(); err != nil {
+		return err
+	}
+	t := binary.LittleEndian.AppendUint32(nil, z.crc)
+	_, err := z.dst.Write(binary.LittleEndian.AppendUint32(t, z.size))
+	return err
+}
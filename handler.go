@@ -0,0 +1,161 @@
+package compress
+
+import (
+	"net/http"
+	"strings"
+)
+
+// minCompressSize responses smaller than this are sent uncompressed.
+const minCompressSize = 1024
+
+// handlerAlgorithms used by CompressHandler unless specified, in order of preference.
+var handlerAlgorithms = []string{"zstd", "brotli", "gzip", "zlib"}
+
+// compressedTypes content types that are already compressed.
+var compressedTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zstd",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+}
+
+// CompressHandler compressing responses using the best algorithm accepted by the client.
+// Algorithms are picked from algos in order of preference, defaults to zstd, brotli, gzip and zlib.
+// Responses smaller than 1KB, already compressed content types or responses with a Content-Encoding are sent as is.
+func CompressHandler(next http.Handler, algos ...string) http.Handler {
+	if len(algos) == 0 {
+		algos = handlerAlgorithms
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		name := negotiate(r.Header.Get("Accept-Encoding"), algos)
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, name: name}
+		defer func() {
+			_ = cw.close()
+		}()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter buffers the start of the response until it's known if it should be compressed.
+type compressWriter struct {
+	http.ResponseWriter
+	name    string
+	status  int
+	buf     []byte
+	started bool
+	encoder Encoder
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if !w.started && w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressWriter) Write(v []byte) (int, error) {
+	if w.started {
+		if w.encoder != nil {
+			return w.encoder.Write(v)
+		}
+		return w.ResponseWriter.Write(v)
+	}
+
+	w.buf = append(w.buf, v...)
+	if len(w.buf) >= minCompressSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(v), nil
+}
+
+// Flush compressed data to the client, forcing the compression decision if nothing has been sent yet.
+func (w *compressWriter) Flush() {
+	if !w.started {
+		if err := w.start(true); err != nil {
+			return
+		}
+	}
+
+	if w.encoder != nil {
+		if err := w.encoder.Flush(); err != nil {
+			return
+		}
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// start writing the header and buffered data, compressed if allowed and the content type isn't already compressed.
+func (w *compressWriter) start(allowed bool) error {
+	w.started = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if allowed && h.Get("Content-Encoding") == "" && !compressedType(h.Get("Content-Type")) {
+		e, err := GetEncoder(w.name, w.ResponseWriter)
+		if err != nil {
+			return err
+		}
+		w.encoder = e
+		h.Set("Content-Encoding", ToContentEncoding(w.name))
+		h.Del("Content-Length")
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// close the encoder, a response that never reached minCompressSize is sent uncompressed.
+func (w *compressWriter) close() error {
+	if !w.started {
+		return w.start(false)
+	}
+
+	if w.encoder == nil {
+		return nil
+	}
+
+	err := w.encoder.Close()
+	PutEncoder(w.name, w.encoder)
+	w.encoder = nil
+	return err
+}
+
+func compressedType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, t := range compressedTypes {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package compress_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestCompressHandler(t *testing.T) {
+	body := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+	h := compress.CompressHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body[:100])
+		_, _ = w.Write(body[100:])
+	}), "brotli", "gzip")
+
+	tests := []struct {
+		accept string
+		enc    string
+	}{
+		{"gzip", "gzip"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"*", "br"},
+		{"gzip;q=0, deflate", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if test.accept != "" {
+			req.Header.Set("Accept-Encoding", test.accept)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%q expected vary header got %q", test.accept, rec.Header().Get("Vary"))
+		}
+
+		enc := rec.Header().Get("Content-Encoding")
+		if enc != test.enc {
+			t.Errorf("%q expected content encoding %q got %q", test.accept, test.enc, enc)
+			continue
+		}
+
+		got := rec.Body.Bytes()
+		if enc != "" {
+			name, err := compress.FromContentEncoding(enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a, err := compress.NewAlgorithm(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err = a.Decode(got); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if !bytes.Equal(body, got) {
+			t.Errorf("%q response doesn't match expected value", test.accept)
+		}
+	}
+}
+
+func TestCompressHandlerSkip(t *testing.T) {
+	tests := map[string]http.HandlerFunc{
+		"small": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("abc123\n"))
+		},
+		"compressed": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(bytes.Repeat([]byte{0}, 4096))
+		},
+	}
+
+	for name, fn := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		compress.CompressHandler(fn).ServeHTTP(rec, req)
+
+		if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+			t.Errorf("%s response shouldn't be compressed got %q", name, enc)
+		}
+	}
+}
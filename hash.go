@@ -0,0 +1,53 @@
+package compress
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"hash"
+	"io"
+)
+
+// EncodeHash algorithm while writing the uncompressed data to h.
+func EncodeHash(a Algorithm, v []byte, h hash.Hash) ([]byte, error) {
+	var buf bytes.Buffer
+	e, err := a.NewEncoder(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.MultiWriter(h, e).Write(v); err != nil {
+		_ = e.Close()
+		return nil, err
+	}
+
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeVerify algorithm while writing the decoded data to h, returns ErrDigestMismatch if the digest isn't want.
+// The digests are compared in constant time.
+func DecodeVerify(a Algorithm, v []byte, h hash.Hash, want []byte) ([]byte, error) {
+	d, err := a.NewDecoder(bytes.NewReader(v))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := copyBuffer(io.MultiWriter(&buf, h), d, a.HelperOptions().bufferSize); err != nil {
+		_ = d.Close()
+		return nil, err
+	}
+
+	if err := d.Close(); err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare(h.Sum(nil), want) != 1 {
+		return nil, ErrDigestMismatch
+	}
+
+	return buf.Bytes(), nil
+}
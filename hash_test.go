@@ -0,0 +1,59 @@
+package compress_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeHash(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []byte("abc123\ndef456\n")
+	h := sha256.New()
+	encoded, err := compress.EncodeHash(a, exp, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sum := sha256.Sum256(exp); !bytes.Equal(sum[:], h.Sum(nil)) {
+		t.Error("hash doesn't match digest of the uncompressed data")
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded output doesn't match original")
+	}
+}
+
+func TestDecodeVerify(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 100)
+	sum := sha256.Sum256(exp)
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := compress.DecodeVerify(a, encoded, sha256.New(), sum[:]); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	sum[0]++
+	if _, err := compress.DecodeVerify(a, encoded, sha256.New(), sum[:]); err != compress.ErrDigestMismatch {
+		t.Errorf("expected %v got %v", compress.ErrDigestMismatch, err)
+	}
+}
@@ -0,0 +1,114 @@
+package compress
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Header metadata stored in the compressed stream.
+type Header struct {
+	Name    string
+	Comment string
+	ModTime time.Time
+	OS      byte
+}
+
+// headerDecoder decoder exposing the stream header.
+type headerDecoder interface {
+	Header() *Header
+}
+
+// DecodeWithHeader algorithm returning the stream header.
+// Header is nil for algorithms without one, gzip is currently the only one that has it.
+func DecodeWithHeader(a Algorithm, v []byte) ([]byte, *Header, error) {
+	d, err := a.NewDecoder(bytes.NewBuffer(v))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var h *Header
+	if hd, ok := d.(headerDecoder); ok {
+		h = hd.Header()
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, d); err != nil {
+		_ = d.Close()
+		return nil, nil, err
+	}
+
+	if err := d.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), h, nil
+}
+
+// HeaderInfo fixed fields of the stream header, parsed without decoding.
+type HeaderInfo struct {
+	// Level hint from FLEVEL for zlib or XFL for gzip, one of BestSpeed, 2, DefaultCompression or BestCompression.
+	Level Level
+
+	// Dictionary required to decode, the FDICT bit for zlib, with the Adler32 of it as DictionaryID.
+	Dictionary   bool
+	DictionaryID uint32
+
+	// Flags FTEXT, FHCRC, FEXTRA, FNAME and FCOMMENT for gzip.
+	Flags byte
+}
+
+// inspectors algorithms with a header parsed by InspectHeader.
+var inspectors = map[string]func(v []byte) (HeaderInfo, error){
+	"bgzf": inspectGzip,
+	"gzip": inspectGzip,
+	"zlib": inspectZlib,
+}
+
+func inspectGzip(v []byte) (HeaderInfo, error) {
+	if len(v) < 10 {
+		return HeaderInfo{}, io.ErrUnexpectedEOF
+	}
+	if v[0] != 0x1f || v[1] != 0x8b || v[2] != 8 {
+		return HeaderInfo{}, ErrUnknownFormat
+	}
+
+	h := HeaderInfo{Level: DefaultCompression, Flags: v[3]}
+	switch v[8] {
+	case 2:
+		h.Level = BestCompression
+	case 4:
+		h.Level = BestSpeed
+	}
+	return h, nil
+}
+
+func inspectZlib(v []byte) (HeaderInfo, error) {
+	if len(v) < 2 {
+		return HeaderInfo{}, io.ErrUnexpectedEOF
+	}
+	if v[0]&0x0f != 8 || binary.BigEndian.Uint16(v)%31 != 0 {
+		return HeaderInfo{}, ErrUnknownFormat
+	}
+
+	h := HeaderInfo{Level: []Level{BestSpeed, 2, DefaultCompression, BestCompression}[v[1]>>6]}
+	if v[1]&0x20 != 0 {
+		if len(v) < 6 {
+			return HeaderInfo{}, io.ErrUnexpectedEOF
+		}
+		h.Dictionary = true
+		h.DictionaryID = binary.BigEndian.Uint32(v[2:])
+	}
+	return h, nil
+}
+
+// InspectHeader algorithm parsing the fixed fields of the header in v, to decide how to decode it.
+// Supported by bgzf, gzip and zlib, other algorithms return ErrHeaderFieldUnsupported.
+func InspectHeader(a Algorithm, v []byte) (HeaderInfo, error) {
+	inspect, ok := inspectors[a.Name()]
+	if !ok {
+		return HeaderInfo{}, ErrHeaderFieldUnsupported
+	}
+	return inspect(v)
+}
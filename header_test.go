@@ -0,0 +1,80 @@
+package compress_test
+
+import (
+	"bytes"
+	"hash/adler32"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestDecodeWithHeader(t *testing.T) {
+	exp := []byte("abc123\ndef456\n")
+	a, err := compress.NewAlgorithm("gzip", compress.WithName("abc.txt"), compress.WithComment("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, h, err := compress.DecodeWithHeader(a, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(exp, got) {
+		t.Error("decoded output doesn't match original")
+	}
+
+	if h == nil {
+		t.Fatal("header should not be nil")
+	}
+
+	if h.Name != "abc.txt" || h.Comment != "abc" {
+		t.Errorf("unexpected header: %+v", h)
+	}
+}
+
+func TestInspectHeader(t *testing.T) {
+	a := compress.MustNewAlgorithm("zlib", compress.WithLevel(compress.BestCompression))
+	encoded, err := a.Encode([]byte("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, err := compress.InspectHeader(a, encoded); err != nil {
+		t.Error(err)
+	} else if h.Level != compress.BestCompression || h.Dictionary {
+		t.Errorf("expected best compression without dictionary got %+v", h)
+	}
+
+	dict := []byte("abcdef")
+	a = compress.MustNewAlgorithm("zlib", compress.WithDictionary(dict))
+	if encoded, err = a.Encode([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := compress.InspectHeader(a, encoded); err != nil {
+		t.Error(err)
+	} else if h.Level != compress.DefaultCompression || !h.Dictionary || h.DictionaryID != adler32.Checksum(dict) {
+		t.Errorf("expected default compression with dictionary got %+v", h)
+	}
+
+	a = compress.MustNewAlgorithm("gzip", compress.WithName("abc.txt"), compress.WithLevel(compress.BestSpeed))
+	if encoded, err = a.Encode([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := compress.InspectHeader(a, encoded); err != nil {
+		t.Error(err)
+	} else if h.Level != compress.BestSpeed || h.Flags != 0x08 {
+		t.Errorf("expected best speed with FNAME got %+v", h)
+	}
+
+	if _, err := compress.InspectHeader(a, []byte("abcdefghijkl")); err != compress.ErrUnknownFormat {
+		t.Errorf("expected %v got %v", compress.ErrUnknownFormat, err)
+	}
+	if _, err := compress.InspectHeader(compress.MustNewAlgorithm("lz4"), encoded); err != compress.ErrHeaderFieldUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrHeaderFieldUnsupported, err)
+	}
+}
@@ -0,0 +1,96 @@
+package compress
+
+import (
+	"strconv"
+	"strings"
+)
+
+// contentEncodings HTTP content-coding tokens mapped to algorithm names.
+// The "deflate" token is the zlib format (RFC 9110 section 8.4.1.2), not raw flate,
+// even though some servers incorrectly send raw flate.
+var contentEncodings = map[string]string{
+	"gzip":     "gzip",
+	"x-gzip":   "gzip",
+	"deflate":  "zlib",
+	"br":       "brotli",
+	"zstd":     "zstd",
+	"identity": "store",
+}
+
+// FromContentEncoding algorithm name for an HTTP Content-Encoding or Accept-Encoding token, case-insensitive.
+// Returns ErrUnknownFormat if the token is unknown or the algorithm isn't registered.
+func FromContentEncoding(enc string) (string, error) {
+	name, ok := contentEncodings[strings.ToLower(strings.TrimSpace(enc))]
+	if !ok {
+		return "", ErrUnknownFormat
+	}
+
+	if _, ok := lookup(name); !ok {
+		return "", ErrUnknownFormat
+	}
+	return name, nil
+}
+
+// ToContentEncoding HTTP content-coding token for an algorithm name, empty if there is none.
+func ToContentEncoding(name string) string {
+	switch name {
+	case "gzip":
+		return "gzip"
+	case "zlib":
+		return "deflate"
+	case "brotli":
+		return "br"
+	case "zstd":
+		return "zstd"
+	case "store":
+		return "identity"
+	}
+	return ""
+}
+
+// acceptEncodings parse an Accept-Encoding header into tokens and their quality value.
+func acceptEncodings(header string) map[string]float64 {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tok := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tok == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[tok] = q
+	}
+	return accepted
+}
+
+// negotiate algorithm with the highest quality value in the Accept-Encoding header, ties use the order of names.
+// Returns an empty string if none of the registered names are accepted.
+func negotiate(header string, names []string) string {
+	accepted := acceptEncodings(header)
+	best, bestQ := "", 0.0
+	for _, name := range names {
+		tok := ToContentEncoding(name)
+		if tok == "" || tok == "identity" {
+			continue
+		}
+
+		q, ok := accepted[tok]
+		if !ok {
+			q = accepted["*"]
+		}
+
+		if _, registered := lookup(name); registered && q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
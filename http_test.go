@@ -0,0 +1,41 @@
+package compress_test
+
+import (
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestContentEncoding(t *testing.T) {
+	tests := []struct {
+		enc  string
+		name string
+	}{
+		{"gzip", "gzip"},
+		{"x-gzip", "gzip"},
+		{" GZIP ", "gzip"},
+		// Deflate is zlib wrapped, not raw flate.
+		{"deflate", "zlib"},
+		{"br", "brotli"},
+		{"zstd", "zstd"},
+		{"identity", "store"},
+	}
+
+	for _, test := range tests {
+		if name, err := compress.FromContentEncoding(test.enc); err != nil {
+			t.Error(err)
+		} else if name != test.name {
+			t.Errorf("%q expected %s got %s", test.enc, test.name, name)
+		}
+	}
+
+	if _, err := compress.FromContentEncoding("compress"); err != compress.ErrUnknownFormat {
+		t.Errorf("expected %v got %v", compress.ErrUnknownFormat, err)
+	}
+
+	for name, enc := range map[string]string{"gzip": "gzip", "zlib": "deflate", "brotli": "br", "zstd": "zstd", "flate": ""} {
+		if got := compress.ToContentEncoding(name); got != enc {
+			t.Errorf("%s expected %q got %q", name, enc, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package trailer reader keeping the last bytes read, so a decoder can verify the trailer of a stream.
+package trailer
+
+import (
+	"bufio"
+	"io"
+)
+
+// Reader keeps the last size bytes read, which is the trailer when the stream ends. It implements
+// io.ByteReader so compress/flate based decoders don't read ahead of the trailer.
+type Reader struct {
+	reader *bufio.Reader
+	last   []byte
+	parse  func(trailer []byte) uint32
+}
+
+// NewReader reader keeping a trailer of size bytes, parse returns the checksum stored in it.
+func NewReader(r io.Reader, size int, parse func(trailer []byte) uint32) *Reader {
+	return &Reader{reader: bufio.NewReader(r), last: make([]byte, size), parse: parse}
+}
+
+func (t *Reader) push(v []byte) {
+	if len(v) >= len(t.last) {
+		copy(t.last, v[len(v)-len(t.last):])
+		return
+	}
+	copy(t.last, t.last[len(v):])
+	copy(t.last[len(t.last)-len(v):], v)
+}
+
+func (t *Reader) Read(v []byte) (int, error) {
+	n, err := t.reader.Read(v)
+	t.push(v[:n])
+	return n, err
+}
+
+func (t *Reader) ReadByte() (byte, error) {
+	b, err := t.reader.ReadByte()
+	if err == nil {
+		t.push([]byte{b})
+	}
+	return b, err
+}
+
+// Checksum stored in the trailer.
+func (t *Reader) Checksum() uint32 {
+	return t.parse(t.last)
+}
@@ -0,0 +1,53 @@
+package compress
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// NewLimitedDecoder algorithm returning a decoder that returns ErrDecodedSizeExceeded from the Read
+// that would exceed max bytes of output, up to max bytes are returned. Unlike DecodeLimit it stops
+// decoding early, which makes it usable for streaming. A negative max returns ErrInvalidLimit.
+func NewLimitedDecoder(a Algorithm, r io.Reader, max int64) (Decoder, error) {
+	if max < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLimit, max)
+	}
+
+	d, err := a.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	return &limitedDecoder{Decoder: d, max: max}, nil
+}
+
+type limitedDecoder struct {
+	Decoder
+	max int64
+	n   int64
+}
+
+func (d *limitedDecoder) Read(v []byte) (int, error) {
+	// Read one byte past the limit to know if it's exceeded, there is no byte past math.MaxInt64.
+	if remaining := d.max - d.n; remaining < math.MaxInt64 && int64(len(v)) > remaining+1 {
+		v = v[:remaining+1]
+	}
+
+	n, err := d.Decoder.Read(v)
+	if d.n+int64(n) > d.max {
+		n = int(d.max - d.n)
+		d.n = d.max
+		return n, ErrDecodedSizeExceeded
+	}
+	d.n += int64(n)
+	return n, err
+}
+
+func (d *limitedDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, struct{ io.Reader }{d})
+}
+
+func (d *limitedDecoder) Reset(r io.Reader) error {
+	d.n = 0
+	return d.Decoder.Reset(r)
+}
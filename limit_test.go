@@ -0,0 +1,99 @@
+package compress_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestNewLimitedDecoder(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The limit is inclusive.
+	d, err := compress.NewLimitedDecoder(a, bytes.NewReader(encoded), int64(len(exp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ioutil.ReadAll(d); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	max := int64(len(exp) - 1)
+	if d, err = compress.NewLimitedDecoder(a, bytes.NewReader(encoded), max); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]byte, max)
+	if _, err := io.ReadFull(d, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := d.Read(make([]byte, 1)); err != compress.ErrDecodedSizeExceeded || n != 0 {
+		t.Errorf("reading one byte past the limit expected %v got %d, %v", compress.ErrDecodedSizeExceeded, n, err)
+	}
+}
+
+func TestNewLimitedDecoderBounds(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := compress.NewLimitedDecoder(a, bytes.NewReader(encoded), math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ioutil.ReadAll(d); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	if d, err = compress.NewLimitedDecoder(a, bytes.NewReader(encoded), 0); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.Read(make([]byte, 16)); err != compress.ErrDecodedSizeExceeded || n != 0 {
+		t.Errorf("expected %v got %d, %v", compress.ErrDecodedSizeExceeded, n, err)
+	}
+
+	empty, err := a.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err = compress.NewLimitedDecoder(a, bytes.NewReader(empty), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ioutil.ReadAll(d); err != nil || len(got) != 0 {
+		t.Errorf("expected empty output got %d bytes, %v", len(got), err)
+	}
+
+	if _, err := compress.NewLimitedDecoder(a, bytes.NewReader(encoded), -1); !errors.Is(err, compress.ErrInvalidLimit) {
+		t.Errorf("expected %v got %v", compress.ErrInvalidLimit, err)
+	}
+}
@@ -0,0 +1,251 @@
+package lz4
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/pierrec/lz4/v4"
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.DefaultCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
+type lz4Algorithm struct {
+	compress.Options
+
+	level       compress.Level
+	concurrency int
+	blockSize   int
+	noChecksum  bool
+}
+
+type lz4Encoder struct {
+	writer *lz4.Writer
+}
+
+type lz4Decoder struct {
+	reader *lz4.Reader
+}
+
+func (a *lz4Algorithm) NewAlgorithm() compress.Algorithm {
+	return &lz4Algorithm{level: compress.DefaultCompression}
+}
+
+func (a *lz4Algorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *lz4Algorithm) Name() string {
+	return "lz4"
+}
+
+func (a *lz4Algorithm) Ext() string {
+	return "lz4"
+}
+
+func (a *lz4Algorithm) String() string {
+	return fmt.Sprintf("lz4(level=%d, block=%d, concurrency=%d)", a.level, a.blockSize, a.concurrency)
+}
+
+func (a *lz4Algorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapConcurrency | compress.CapFlush | compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *lz4Algorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+func (a *lz4Algorithm) DefaultLevel() compress.Level {
+	return compress.BestSpeed
+}
+
+func (a *lz4Algorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm lz4 level %d", level)
+	}
+	a.level = level
+	return nil
+}
+
+func (a *lz4Algorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm lz4")
+}
+
+// SetDeterministic is a no-op, the output of lz4 is always the same for the same input.
+func (a *lz4Algorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *lz4Algorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+func (a *lz4Algorithm) SetNoChecksum(noChecksum bool) error {
+	a.noChecksum = noChecksum
+	return nil
+}
+
+func (a *lz4Algorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm lz4")
+}
+
+func (a *lz4Algorithm) SetConcurrency(n int) error {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	a.concurrency = n
+	return nil
+}
+
+// SetBlockSize lz4 frame block size of 64KB, 256KB, 1MB or 4MB.
+func (a *lz4Algorithm) SetBlockSize(n int) error {
+	switch lz4.BlockSize(n) {
+	case lz4.Block64Kb, lz4.Block256Kb, lz4.Block1Mb, lz4.Block4Mb:
+		a.blockSize = n
+		return nil
+	}
+	return errors.Wrapf(compress.ErrInvalidBlockSize, "algorithm lz4 block size %d must be 64KB, 256KB, 1MB or 4MB", n)
+}
+
+// SetMaxMemory is a no-op, blocks are at most 4MB.
+func (a *lz4Algorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm lz4 max memory %d", n)
+	}
+	return nil
+}
+
+// compressionLevel maps a generic compression level onto lz4's fast mode or
+// one of its high compression levels.
+func compressionLevel(level compress.Level) lz4.CompressionLevel {
+	if level <= compress.BestSpeed {
+		return lz4.Fast
+	}
+	if level > compress.BestCompression {
+		level = compress.BestCompression
+	}
+	return lz4.CompressionLevel(1 << (8 + uint(level)))
+}
+
+func (a *lz4Algorithm) Validate() error {
+	return nil
+}
+
+func (a *lz4Algorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	opts := []lz4.Option{lz4.CompressionLevelOption(compressionLevel(a.level))}
+	if a.concurrency > 0 {
+		opts = append(opts, lz4.ConcurrencyOption(a.concurrency))
+	}
+	if a.blockSize > 0 {
+		opts = append(opts, lz4.BlockSizeOption(lz4.BlockSize(a.blockSize)))
+	}
+	if a.noChecksum {
+		opts = append(opts, lz4.ChecksumOption(false))
+	}
+
+	e := &lz4Encoder{writer: lz4.NewWriter(w)}
+	if err := e.writer.Apply(opts...); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (a *lz4Algorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+func (e *lz4Encoder) Write(v []byte) (int, error) {
+	return e.writer.Write(v)
+}
+
+func (e *lz4Encoder) ReadFrom(r io.Reader) (int64, error) {
+	return e.writer.ReadFrom(r)
+}
+
+func (e *lz4Encoder) Reset(w io.Writer) error {
+	e.writer.Reset(w)
+	return nil
+}
+
+func (e *lz4Encoder) Flush() error {
+	return e.writer.Flush()
+}
+
+func (e *lz4Encoder) Close() error {
+	return e.writer.Close()
+}
+
+func (a *lz4Algorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	return &lz4Decoder{reader: lz4.NewReader(r)}, nil
+}
+
+func (a *lz4Algorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *lz4Decoder) Read(v []byte) (int, error) {
+	return d.reader.Read(v)
+}
+
+func (d *lz4Decoder) WriteTo(w io.Writer) (int64, error) {
+	return d.reader.WriteTo(w)
+}
+
+func (d *lz4Decoder) Reset(r io.Reader) error {
+	d.reader.Reset(r)
+	return nil
+}
+
+func (d *lz4Decoder) Close() error {
+	return nil
+}
+
+func init() {
+	compress.Register("lz4", &lz4Algorithm{})
+}
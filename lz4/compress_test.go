@@ -0,0 +1,64 @@
+package lz4
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// testdata/fox.txt.lz4 is a multi-block frame (64KB blocks, content checksum)
+// produced outside of this package from testdata/fox.txt.
+func TestDecodeFixture(t *testing.T) {
+	exp, err := ioutil.ReadFile("testdata/fox.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := ioutil.ReadFile("testdata/fox.txt.lz4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := compress.NewAlgorithm("lz4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded fixture doesn't match expected value")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	if _, err := compress.NewAlgorithm("lz4", compress.WithLevel(compress.HuffmanOnly)); err == nil {
+		t.Error("huffman only should not be a supported level")
+	}
+}
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.LZ4"); err != nil {
+		t.Error(err)
+	} else if name != "lz4" {
+		t.Errorf("expected lz4 got %s", name)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.DefaultCompression, compress.BestSpeed, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("lz4", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{compress.HuffmanOnly, compress.NoCompression, 10} {
+		if _, err := compress.NewAlgorithm("lz4", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
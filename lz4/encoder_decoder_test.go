@@ -0,0 +1,32 @@
+package lz4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
+
+	for _, level := range []compress.Level{compress.BestSpeed, compress.BestCompression} {
+		a, err := compress.NewAlgorithm("lz4", compress.WithLevel(level))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(exp)
+		if err != nil {
+			t.Error(errors.Wrap(err, "test encode"))
+		}
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Error(errors.Wrap(err, "test decode"))
+		} else if !bytes.Equal(exp, got) {
+			t.Error(errors.Errorf("test decode doesn't match expected value"))
+		}
+	}
+}
@@ -2,7 +2,9 @@ package lzw
 
 import (
 	"compress/lzw"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -10,43 +12,163 @@ import (
 )
 
 type lzwAlgorithm struct {
-	endian   compress.Endian
+	compress.Options
+
+	order    lzw.Order
 	litWidth int
 }
 
 type lzwEncoder struct {
-	writer io.WriteCloser
+	writer   io.WriteCloser
+	order    lzw.Order
+	litWidth int
 }
 
 type lzwDecoder struct {
-	reader io.ReadCloser
+	reader   io.ReadCloser
+	order    lzw.Order
+	litWidth int
 }
 
 func (a *lzwAlgorithm) NewAlgorithm() compress.Algorithm {
-	return &lzwAlgorithm{}
+	return &lzwAlgorithm{litWidth: 8}
+}
+
+func (a *lzwAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *lzwAlgorithm) Name() string {
+	return "lzw"
 }
 
 func (a *lzwAlgorithm) Ext() string {
 	return "lzw"
 }
 
+func (a *lzwAlgorithm) String() string {
+	endian := "little"
+	if a.order == lzw.MSB {
+		endian = "big"
+	}
+	return fmt.Sprintf("lzw(litwidth=%d, endian=%s)", a.litWidth, endian)
+}
+
+func (a *lzwAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLitWidth | compress.CapEndian
+}
+
+func (a *lzwAlgorithm) ValidLevels() []compress.Level {
+	return nil
+}
+
+func (a *lzwAlgorithm) DefaultLevel() compress.Level {
+	return compress.NoCompression
+}
+
 func (a *lzwAlgorithm) SetLevel(level compress.Level) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lzw")
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm lzw level %d", level)
+	}
+	return nil
 }
 
 func (a *lzwAlgorithm) SetEndian(endian compress.Endian) error {
-	a.endian = endian
+	switch endian {
+	case compress.Little:
+		a.order = lzw.LSB
+	case compress.Big:
+		a.order = lzw.MSB
+	default:
+		return errors.Wrapf(compress.ErrInvalidEndian, "algorithm lzw endian %d", endian)
+	}
 	return nil
 }
 
 func (a *lzwAlgorithm) SetLitWidth(width int) error {
+	if width < 2 || width > 8 {
+		return errors.Wrapf(compress.ErrInvalidLitWidth, "algorithm lzw lit width %d", width)
+	}
 	a.litWidth = width
 	return nil
 }
 
+func (a *lzwAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm lzw")
+}
+
+// SetDeterministic is a no-op, the output of lzw is always the same for the same input.
+func (a *lzwAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *lzwAlgorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+// SetNoChecksum is a no-op, lzw has no checksum.
+func (a *lzwAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return nil
+}
+
+func (a *lzwAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm lzw")
+}
+
+func (a *lzwAlgorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm lzw")
+}
+
+// SetMaxMemory is a no-op, the code table is fixed.
+func (a *lzwAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm lzw max memory %d", n)
+	}
+	return nil
+}
+
+func (a *lzwAlgorithm) Validate() error {
+	return nil
+}
+
 func (a *lzwAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
 	return &lzwEncoder{
-		writer: lzw.NewWriter(w, lzw.Order(a.endian), a.litWidth),
+		writer:   lzw.NewWriter(w, a.order, a.litWidth),
+		order:    a.order,
+		litWidth: a.litWidth,
 	}, nil
 }
 
@@ -58,13 +180,28 @@ func (e *lzwEncoder) Write(v []byte) (int, error) {
 	return e.writer.Write(v)
 }
 
+func (e *lzwEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
+}
+
+func (e *lzwEncoder) Reset(w io.Writer) error {
+	e.writer = lzw.NewWriter(w, e.order, e.litWidth)
+	return nil
+}
+
+func (e *lzwEncoder) Flush() error {
+	return errors.Wrap(compress.ErrFlushUnsupported, "algorithm lzw")
+}
+
 func (e *lzwEncoder) Close() error {
 	return e.writer.Close()
 }
 
 func (a *lzwAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
 	return &lzwDecoder{
-		reader: lzw.NewReader(r, lzw.Order(a.endian), a.litWidth),
+		reader:   lzw.NewReader(r, a.order, a.litWidth),
+		order:    a.order,
+		litWidth: a.litWidth,
 	}, nil
 }
 
@@ -76,6 +213,15 @@ func (d *lzwDecoder) Read(v []byte) (int, error) {
 	return d.reader.Read(v)
 }
 
+func (d *lzwDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *lzwDecoder) Reset(r io.Reader) error {
+	d.reader = lzw.NewReader(r, d.order, d.litWidth)
+	return nil
+}
+
 func (d *lzwDecoder) Close() error {
 	return d.reader.Close()
 }
@@ -0,0 +1,81 @@
+package lzw
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.LZW"); err != nil {
+		t.Error(err)
+	} else if name != "lzw" {
+		t.Errorf("expected lzw got %s", name)
+	}
+}
+
+func TestNewAlgorithmByExt(t *testing.T) {
+	a, err := compress.NewAlgorithmByExt("abc.txt.lzw", compress.WithLitWidth(8), compress.WithEndian(compress.Big))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Ext() != "lzw" {
+		t.Errorf("expected lzw got %s", a.Ext())
+	}
+
+	if _, err := compress.NewAlgorithmByExt("abc.txt.lzw", compress.WithLevel(compress.BestSpeed)); err == nil {
+		t.Error("options should be applied to the constructed algorithm")
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	a, err := compress.NewAlgorithm("lzw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(a.ValidLevels()) != 0 {
+		t.Errorf("expected no valid levels got %v", a.ValidLevels())
+	}
+
+	if err := a.SetLevel(compress.BestSpeed); errors.Cause(err) != compress.ErrInvalidLevel {
+		t.Errorf("expected %v got %v", compress.ErrInvalidLevel, err)
+	}
+}
+
+func TestConcurrencyUnsupported(t *testing.T) {
+	if _, err := compress.NewAlgorithm("lzw", compress.WithConcurrency(4)); errors.Cause(err) != compress.ErrConcurrencyUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrConcurrencyUnsupported, err)
+	}
+}
+
+func TestEncoderFlushUnsupported(t *testing.T) {
+	e, err := compress.NewEncoder("lzw", &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Flush(); errors.Cause(err) != compress.ErrFlushUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrFlushUnsupported, err)
+	}
+}
+
+func TestSetLitWidth(t *testing.T) {
+	for _, width := range []int{1, 9} {
+		if _, err := compress.NewAlgorithm("lzw", compress.WithLitWidth(width)); errors.Cause(err) != compress.ErrInvalidLitWidth {
+			t.Errorf("lit width %d expected %v got %v", width, compress.ErrInvalidLitWidth, err)
+		} else if !strings.Contains(err.Error(), strconv.Itoa(width)) {
+			t.Errorf("error should include the lit width got %v", err)
+		}
+	}
+
+	if _, err := compress.NewAlgorithm("lzw", compress.WithLitWidth(5)); err != nil {
+		t.Error(err)
+	}
+}
@@ -12,14 +12,47 @@ import (
 func TestEncodeDecode(t *testing.T) {
 	exp := []byte("abc123\ndef456\nabc123\ndef456\nabc123\ndef456\n")
 
-	encoded, err := compress.Encode("lzw", exp)
+	a, err := compress.NewAlgorithm("lzw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
 	if err != nil {
 		t.Error(errors.Wrap(err, "test encode"))
 	}
 
-	if got, err := compress.Decode("lzw", encoded); err != nil {
+	if got, err := a.Decode(encoded); err != nil {
 		t.Error(errors.Wrap(err, "test decode"))
 	} else if !bytes.Equal(exp, got) {
 		t.Error(errors.Errorf("test decode doesn't match expected value"))
 	}
 }
+
+func TestEncodeDecodeLitWidthEndian(t *testing.T) {
+	for _, width := range []int{2, 8} {
+		// Input bytes must fit in the lit width.
+		exp := make([]byte, 1000)
+		for i := range exp {
+			exp[i] = byte(i * 7 % 13 % (1 << uint(width)))
+		}
+
+		for _, endian := range []compress.Endian{compress.Little, compress.Big} {
+			a, err := compress.NewAlgorithm("lzw", compress.WithLitWidth(width), compress.WithEndian(endian))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encoded, err := a.Encode(exp)
+			if err != nil {
+				t.Error(errors.Wrap(err, "test encode"))
+			}
+
+			if got, err := a.Decode(encoded); err != nil {
+				t.Error(errors.Wrap(err, "test decode"))
+			} else if !bytes.Equal(exp, got) {
+				t.Errorf("lit width %d endian %d decode doesn't match expected value", width, endian)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+)
+
+// multistreamDecoder decoder that can stop at the end of each member of a concatenated stream.
+type multistreamDecoder interface {
+	Multistream(ok bool)
+}
+
+// DecodeMembers algorithm returning the payload of each member in a concatenated stream separately.
+// Algorithms without multistream support return a single member. The bgzf EOF marker isn't returned.
+func DecodeMembers(a Algorithm, v []byte) ([][]byte, error) {
+	r := bytes.NewReader(v)
+	d, err := a.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, ok := d.(multistreamDecoder)
+	members := [][]byte{}
+	for {
+		if ok {
+			ms.Multistream(false)
+		}
+
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, d); err != nil {
+			_ = d.Close()
+			return nil, err
+		}
+		members = append(members, buf.Bytes())
+
+		if !ok {
+			break
+		}
+
+		if err := d.Reset(r); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := d.Close(); err != nil {
+		return nil, err
+	}
+
+	if n := len(members); eofMarkers[a.Name()] && n > 0 && len(members[n-1]) == 0 {
+		members = members[:n-1]
+	}
+
+	return members, nil
+}
+
+// eofMarkers algorithms ending each stream with an empty member.
+var eofMarkers = map[string]bool{
+	"bgzf": true,
+}
+
+// appendable algorithms whose decoders read concatenated streams as one.
+var appendable = map[string]bool{
+	"bgzf":   true,
+	"bzip2":  true,
+	"gzip":   true,
+	"lz4":    true,
+	"s2":     true,
+	"snappy": true,
+	"store":  true,
+	"xz":     true,
+	"zstd":   true,
+}
+
+// EncodeAppend algorithm appending v as a new member to an existing encoded stream,
+// decoding the result returns the concatenated data. Returns ErrAppendUnsupported for
+// algorithms that can't decode concatenated streams, such as brotli.
+func EncodeAppend(a Algorithm, existing []byte, v []byte) ([]byte, error) {
+	if !appendable[a.Name()] {
+		return nil, ErrAppendUnsupported
+	}
+	return EncodeTo(existing, a, v)
+}
+
+// splittable algorithms whose decoders stop at the end of each member, so DecodeMembers can split them.
+var splittable = map[string]bool{
+	"bgzf": true,
+	"gzip": true,
+}
+
+// recordWriter encoder writing a record as a member without ending the stream, bgzf writes an EOF
+// marker on each Close.
+type recordWriter interface {
+	WriteRecord(v []byte) error
+}
+
+// EncodeRecords algorithm encoding each record as a separate member of a concatenated stream, so every
+// record decodes on its own and DecodeMembers returns them. Returns ErrAppendUnsupported for algorithms
+// whose members DecodeMembers can't split, only bgzf and gzip are supported. With bgzf each record must
+// fit in a block. No records is empty output.
+func EncodeRecords(a Algorithm, records [][]byte) ([]byte, error) {
+	if !splittable[a.Name()] {
+		return nil, ErrAppendUnsupported
+	}
+
+	if len(records) == 0 {
+		return []byte{}, nil
+	}
+
+	var buf bytes.Buffer
+	e, err := a.NewEncoder(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if rw, ok := e.(recordWriter); ok {
+		for _, record := range records {
+			if err := rw.WriteRecord(record); err != nil {
+				_ = e.Close()
+				return nil, err
+			}
+		}
+		if err := e.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	for i, record := range records {
+		if i > 0 {
+			if err := e.Reset(&buf); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := e.Write(record); err != nil {
+			_ = e.Close()
+			return nil, err
+		}
+		if err := e.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
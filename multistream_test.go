@@ -0,0 +1,130 @@
+package compress_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestDecodeMembers(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exps := [][]byte{[]byte("abc123\n"), []byte("def456\n"), []byte("ghi789\n")}
+	var concat []byte
+	for _, v := range exps {
+		encoded, err := a.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		concat = append(concat, encoded...)
+	}
+
+	members, err := compress.DecodeMembers(a, concat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(members) != len(exps) {
+		t.Fatalf("expected %d members got %d", len(exps), len(members))
+	}
+
+	for i, exp := range exps {
+		if !bytes.Equal(exp, members[i]) {
+			t.Errorf("member %d expected %q got %q", i, exp, members[i])
+		}
+	}
+}
+
+func TestEncodeAppend(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode([]byte("abc123\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if encoded, err = compress.EncodeAppend(a, encoded, []byte("def456\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if string(got) != "abc123\ndef456\n" {
+		t.Errorf("expected abc123 def456 got %q", got)
+	}
+
+	if _, err := compress.EncodeAppend(compress.MustNewAlgorithm("brotli"), nil, []byte("abc123\n")); err != compress.ErrAppendUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrAppendUnsupported, err)
+	}
+}
+
+func TestEncodeRecords(t *testing.T) {
+	records := [][]byte{[]byte("abc123\n"), {}, []byte("def456\n"), bytes.Repeat([]byte("ghi789\n"), 1000), {}}
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"bgzf", true},
+		{"gzip", true},
+	}
+	for _, name := range algorithms() {
+		if name != "bgzf" && name != "gzip" {
+			tests = append(tests, struct {
+				name string
+				ok   bool
+			}{name, false})
+		}
+	}
+
+	for _, test := range tests {
+		a := compress.MustNewAlgorithm(test.name)
+		encoded, err := compress.EncodeRecords(a, records)
+		if !test.ok {
+			if err != compress.ErrAppendUnsupported {
+				t.Errorf("%s: expected %v got %v", test.name, compress.ErrAppendUnsupported, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		members, err := compress.DecodeMembers(a, encoded)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(members) != len(records) {
+			t.Fatalf("%s: expected %d members got %d", test.name, len(records), len(members))
+		}
+		for i, member := range members {
+			if !bytes.Equal(records[i], member) {
+				t.Errorf("%s: member %d expected %q got %q", test.name, i, records[i], member)
+			}
+		}
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !bytes.Equal(bytes.Join(records, nil), got) {
+			t.Errorf("%s: expected the concatenated records", test.name)
+		}
+
+		if got, err := compress.EncodeRecords(a, nil); err != nil || len(got) != 0 {
+			t.Errorf("%s: expected empty output got %d bytes, %v", test.name, len(got), err)
+		}
+	}
+
+	b := compress.MustNewAlgorithm("bgzf", compress.WithBlockSize(1024))
+	if _, err := compress.EncodeRecords(b, [][]byte{make([]byte, 1025)}); errors.Cause(err) != compress.ErrInvalidBlockSize {
+		t.Errorf("expected %v got %v", compress.ErrInvalidBlockSize, err)
+	}
+}
@@ -0,0 +1,114 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer larger buffers aren't returned to the pool, so a single large payload doesn't pin the memory.
+const maxPooledBuffer = 4 * 1024 * 1024
+
+const (
+	// maxExpansion of deflate, a 258 byte match coded in 2 bits.
+	maxExpansion = 1032
+
+	// maxPreallocate from a decoded size read from the stream.
+	maxPreallocate = 64 * 1024 * 1024
+)
+
+// decodedSizer algorithm that can read the decoded size from the stream without decoding it.
+type decodedSizer interface {
+	DecodedSize(v []byte) (int64, bool)
+}
+
+// capDecodedSize read from n bytes of encoded data to the maximum expansion and maxPreallocate,
+// so a wrong size doesn't allocate more than the data could decode to.
+func capDecodedSize(size int64, n int) int {
+	if max := int64(n) * maxExpansion; size > max {
+		size = max
+	}
+	if size > maxPreallocate {
+		size = maxPreallocate
+	}
+	return int(size)
+}
+
+var (
+	encoderPools = make(map[string]*sync.Pool)
+	decoderPools = make(map[string]*sync.Pool)
+	poolLock     sync.Mutex
+
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+)
+
+// getBuffer from the pool for Encode and Decode, the result must be copied out before putBuffer.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+func pool(pools map[string]*sync.Pool, name string) *sync.Pool {
+	poolLock.Lock()
+	defer poolLock.Unlock()
+	p, ok := pools[name]
+	if !ok {
+		p = &sync.Pool{}
+		pools[name] = p
+	}
+	return p
+}
+
+// GetEncoder from the algorithm pool reset to write to w, a new encoder is created if the pool is empty.
+// Encoders use the default options of the algorithm.
+func GetEncoder(algo string, w io.Writer) (Encoder, error) {
+	if e, ok := pool(encoderPools, algo).Get().(Encoder); ok {
+		if err := e.Reset(w); err == nil {
+			return e, nil
+		}
+	}
+
+	a, err := NewAlgorithm(algo)
+	if err != nil {
+		return nil, err
+	}
+	return a.NewEncoder(w)
+}
+
+// PutEncoder back in the algorithm pool, it should be closed first to flush the stream.
+func PutEncoder(algo string, e Encoder) {
+	pool(encoderPools, algo).Put(e)
+}
+
+// GetDecoder from the algorithm pool reset to read from r, a new decoder is created if the pool is empty.
+// Decoders use the default options of the algorithm.
+func GetDecoder(algo string, r io.Reader) (Decoder, error) {
+	if d, ok := pool(decoderPools, algo).Get().(Decoder); ok {
+		if err := d.Reset(r); err == nil {
+			return d, nil
+		}
+	}
+
+	a, err := NewAlgorithm(algo)
+	if err != nil {
+		return nil, err
+	}
+	return a.NewDecoder(r)
+}
+
+// PutDecoder back in the algorithm pool.
+// Decoders that can't be reset after Close, such as zstd, are discarded on the next GetDecoder.
+func PutDecoder(algo string, d Decoder) {
+	pool(decoderPools, algo).Put(d)
+}
@@ -0,0 +1,76 @@
+package compress_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestPool(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range [][]byte{bytes.Repeat([]byte("abc123\n"), 100), []byte("def456\n")} {
+		var buf bytes.Buffer
+		e, err := compress.GetEncoder("gzip", &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := e.Write(exp); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+		compress.PutEncoder("gzip", e)
+
+		if got, err := a.Decode(buf.Bytes()); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("pooled encoder leaked state, expected %q got %q", exp, got)
+		}
+
+		d, err := compress.GetDecoder("gzip", &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := ioutil.ReadAll(d); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("pooled decoder leaked state, expected %q got %q", exp, got)
+		}
+		compress.PutDecoder("gzip", d)
+	}
+
+	if _, err := compress.GetEncoder("foo", &bytes.Buffer{}); err == nil {
+		t.Error("foo should not be a registered algorithm")
+	}
+}
+
+func BenchmarkPool(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			e, err := compress.GetEncoder("gzip", ioutil.Discard)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			if _, err := e.Write(benchPayload); err != nil {
+				b.Fatal(err)
+			}
+
+			if err := e.Close(); err != nil {
+				b.Fatal(err)
+			}
+			compress.PutEncoder("gzip", e)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package compress
+
+import (
+	"io"
+)
+
+// withProgress wraps r reporting the bytes read to the progress callback of the algorithm, if set.
+func withProgress(a Algorithm, r io.Reader) io.Reader {
+	fn := a.HelperOptions().progress
+	if fn == nil {
+		return r
+	}
+	return &progressReader{reader: r, fn: fn, size: int64(bufferSize(a))}
+}
+
+// progressReader calls fn at most once per size bytes read, and at EOF.
+type progressReader struct {
+	reader io.Reader
+	fn     func(processed int64)
+	size   int64
+	n      int64
+	last   int64
+	done   bool
+}
+
+func (r *progressReader) Read(v []byte) (int, error) {
+	n, err := r.reader.Read(v)
+	r.n += int64(n)
+	if r.n-r.last >= r.size || (err == io.EOF && !r.done) {
+		r.done = err == io.EOF
+		r.last = r.n
+		r.fn(r.n)
+	}
+	return n, err
+}
@@ -0,0 +1,53 @@
+package compress_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestWithProgress(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 10000)
+
+	var calls int
+	var processed int64
+	a, err := compress.NewAlgorithm("gzip", compress.WithBufferSize(4096), compress.WithProgress(func(n int64) {
+		calls++
+		processed = n
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if processed != int64(len(exp)) {
+		t.Errorf("encode progress expected %d got %d", len(exp), processed)
+	}
+
+	if max := len(exp)/4096 + 1; calls < 2 || calls > max {
+		t.Errorf("encode progress expected between 2 and %d calls got %d", max, calls)
+	}
+
+	calls, processed = 0, 0
+	if _, err := a.Decode(encoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if processed != int64(len(encoded)) {
+		t.Errorf("decode progress expected %d got %d", len(encoded), processed)
+	}
+
+	// Nil disables progress.
+	if err := compress.WithProgress(nil)(a); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Encode(exp); err != nil {
+		t.Error(err)
+	}
+}
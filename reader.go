@@ -0,0 +1,62 @@
+package compress
+
+import (
+	"bufio"
+	"io"
+)
+
+// EncodeReader algorithm returning a reader yielding the encoded data of r as it's read.
+// Encoding runs in a goroutine, errors are returned from Read. Close stops the encoding.
+// It blocks while the limit set by SetMaxGoroutines is reached.
+func EncodeReader(a Algorithm, r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	release := acquire()
+	go func() {
+		defer release()
+		_ = pw.CloseWithError(encodeTo(a, pw, withProgress(a, r)))
+	}()
+	return pr
+}
+
+func encodeTo(a Algorithm, w io.Writer, r io.Reader) error {
+	e, err := a.NewEncoder(w)
+	if err != nil {
+		return err
+	}
+
+	if err := copyTimeout(a, e, r); err != nil {
+		_ = e.Close()
+		return err
+	}
+
+	return e.Close()
+}
+
+// DecodeReader algorithm returning a reader yielding the decoded data of r as it's read.
+// Decoder errors, including checksum mismatches, are returned from Read. Close closes the decoder.
+func DecodeReader(a Algorithm, r io.Reader) io.ReadCloser {
+	d, err := a.NewDecoder(withProgress(a, r))
+	if err != nil {
+		return &errReadCloser{err: err}
+	}
+	return d
+}
+
+// NewDecodeScanner algorithm returning a scanner yielding the lines of the decoded data of r.
+// Decoder errors are returned by Err, use Buffer on the scanner for lines longer than bufio.MaxScanTokenSize.
+func NewDecodeScanner(a Algorithm, r io.Reader) *bufio.Scanner {
+	return bufio.NewScanner(DecodeReader(a, r))
+}
+
+// errReadCloser returns err on Read, for decoders that failed to be constructed.
+type errReadCloser struct {
+	err error
+}
+
+func (r *errReadCloser) Read(v []byte) (int, error) {
+	return 0, r.err
+}
+
+func (r *errReadCloser) Close() error {
+	return nil
+}
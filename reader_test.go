@@ -0,0 +1,211 @@
+package compress_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeReader(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 4<<20; i++ {
+		fmt.Fprintf(&buf, "line %d: the quick brown fox jumps over the lazy dog\n", i)
+	}
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := a.Encode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := compress.EncodeReader(a, bytes.NewReader(buf.Bytes()))
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if !bytes.Equal(exp, got) {
+		t.Error("encode reader output doesn't match encode")
+	}
+}
+
+type errReader struct{}
+
+func (r *errReader) Read(v []byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+func TestEncodeReaderError(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := compress.EncodeReader(a, &errReader{})
+	if _, err := ioutil.ReadAll(r); err == nil || err.Error() != "failed" {
+		t.Errorf("expected failed got %v", err)
+	}
+}
+
+func TestDecodeReader(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 4<<20; i++ {
+		fmt.Fprintf(&buf, "line %d: the quick brown fox jumps over the lazy dog\n", i)
+	}
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := a.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := compress.DecodeReader(a, bytes.NewReader(encoded))
+	var got bytes.Buffer
+	v := make([]byte, 1024)
+	for {
+		n, err := r.Read(v)
+		got.Write(v[:n])
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if !bytes.Equal(exp, got.Bytes()) {
+		t.Error("decode reader output doesn't match decode")
+	}
+}
+
+func TestDecodeReaderError(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip", compress.WithChecksumVerify(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ioutil.ReadAll(compress.DecodeReader(a, bytes.NewReader([]byte("not gzip")))); err == nil {
+		t.Error("invalid header should be returned from read")
+	}
+
+	encoded, err := a.Encode([]byte("abc123\ndef456\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded[len(encoded)-8] ^= 0xff
+
+	if _, err := ioutil.ReadAll(compress.DecodeReader(a, bytes.NewReader(encoded))); errors.Cause(err) != compress.ErrChecksumMismatch {
+		t.Errorf("expected %v got %v", compress.ErrChecksumMismatch, err)
+	}
+}
+
+func TestNewDecodeScanner(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&buf, "line %d\n", i)
+	}
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := compress.NewDecodeScanner(a, bytes.NewReader(encoded))
+	i := 0
+	for ; s.Scan(); i++ {
+		if exp := fmt.Sprintf("line %d", i); s.Text() != exp {
+			t.Fatalf("expected %q got %q", exp, s.Text())
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		t.Error(err)
+	}
+
+	if i != 1000 {
+		t.Errorf("expected 1000 lines got %d", i)
+	}
+
+	// Truncated streams return the decoder error.
+	s = compress.NewDecodeScanner(a, bytes.NewReader(encoded[:len(encoded)/2]))
+	for s.Scan() {
+	}
+
+	if err := s.Err(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestSetMaxGoroutines(t *testing.T) {
+	compress.SetMaxGoroutines(1)
+	defer compress.SetMaxGoroutines(0)
+
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := compress.EncodeReader(a, bytes.NewReader([]byte("abc")))
+	started := make(chan io.ReadCloser)
+	go func() {
+		started <- compress.EncodeReader(a, bytes.NewReader([]byte("def")))
+	}()
+
+	select {
+	case <-started:
+		t.Fatal("second encode reader should wait for the first to finish")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := ioutil.ReadAll(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case second := <-started:
+		got, err := ioutil.ReadAll(compress.DecodeReader(a, second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "def" {
+			t.Errorf("expected def got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second encode reader didn't start after the first finished")
+	}
+}
@@ -0,0 +1,259 @@
+package s2
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.DefaultCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
+type s2Algorithm struct {
+	compress.Options
+
+	level       compress.Level
+	concurrency int
+	blockSize   int
+}
+
+// streamIdentifier first chunk of a stream, s2 only writes it with the first data.
+const streamIdentifier = "\xff\x06\x00\x00S2sTwO"
+
+type s2Encoder struct {
+	writer  *s2.Writer
+	dst     io.Writer
+	written bool
+}
+
+type s2Decoder struct {
+	reader *s2.Reader
+}
+
+func (a *s2Algorithm) NewAlgorithm() compress.Algorithm {
+	return &s2Algorithm{level: compress.DefaultCompression}
+}
+
+func (a *s2Algorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *s2Algorithm) Name() string {
+	return "s2"
+}
+
+func (a *s2Algorithm) Ext() string {
+	return "s2"
+}
+
+func (a *s2Algorithm) String() string {
+	return fmt.Sprintf("s2(level=%d, block=%d, concurrency=%d)", a.level, a.blockSize, a.concurrency)
+}
+
+func (a *s2Algorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapConcurrency | compress.CapFlush | compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *s2Algorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+func (a *s2Algorithm) DefaultLevel() compress.Level {
+	return compress.BestSpeed
+}
+
+func (a *s2Algorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm s2 level %d", level)
+	}
+	a.level = level
+	return nil
+}
+
+func (a *s2Algorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm s2")
+}
+
+// SetDeterministic is a no-op, the output of s2 is always the same for the same input.
+func (a *s2Algorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *s2Algorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+func (a *s2Algorithm) SetNoChecksum(noChecksum bool) error {
+	return errors.Wrap(compress.ErrChecksumToggleUnsupported, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm s2")
+}
+
+func (a *s2Algorithm) SetConcurrency(n int) error {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	a.concurrency = n
+	return nil
+}
+
+// SetBlockSize between 4KB and 4MB.
+func (a *s2Algorithm) SetBlockSize(n int) error {
+	if n < 4<<10 || n > 4<<20 {
+		return errors.Wrapf(compress.ErrInvalidBlockSize, "algorithm s2 block size %d must be between 4KB and 4MB", n)
+	}
+	a.blockSize = n
+	return nil
+}
+
+// SetMaxMemory is a no-op, blocks are at most 4MB.
+func (a *s2Algorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm s2 max memory %d", n)
+	}
+	return nil
+}
+
+// writerOptions default mode up to best speed, better up to 8 and best for best compression.
+func (a *s2Algorithm) writerOptions() []s2.WriterOption {
+	var opts []s2.WriterOption
+	switch {
+	case a.level == compress.BestCompression:
+		opts = append(opts, s2.WriterBestCompression())
+	case a.level > compress.BestSpeed:
+		opts = append(opts, s2.WriterBetterCompression())
+	}
+
+	if a.concurrency > 0 {
+		opts = append(opts, s2.WriterConcurrency(a.concurrency))
+	}
+	if a.blockSize > 0 {
+		opts = append(opts, s2.WriterBlockSize(a.blockSize))
+	}
+	return opts
+}
+
+func (a *s2Algorithm) Validate() error {
+	return nil
+}
+
+func (a *s2Algorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	return &s2Encoder{writer: s2.NewWriter(w, a.writerOptions()...), dst: w}, nil
+}
+
+func (a *s2Algorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+func (e *s2Encoder) Write(v []byte) (int, error) {
+	n, err := e.writer.Write(v)
+	e.written = e.written || n > 0
+	return n, err
+}
+
+func (e *s2Encoder) ReadFrom(r io.Reader) (int64, error) {
+	n, err := e.writer.ReadFrom(r)
+	e.written = e.written || n > 0
+	return n, err
+}
+
+func (e *s2Encoder) Reset(w io.Writer) error {
+	e.writer.Reset(w)
+	e.dst = w
+	e.written = false
+	return nil
+}
+
+func (e *s2Encoder) Flush() error {
+	return e.writer.Flush()
+}
+
+func (e *s2Encoder) Close() error {
+	if err := e.writer.Close(); err != nil {
+		return err
+	}
+	if !e.written {
+		// An empty stream still has the identifier, so it can be detected and decoded.
+		_, err := io.WriteString(e.dst, streamIdentifier)
+		return err
+	}
+	return nil
+}
+
+func (a *s2Algorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	return &s2Decoder{reader: s2.NewReader(r)}, nil
+}
+
+func (a *s2Algorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *s2Decoder) Read(v []byte) (int, error) {
+	return d.reader.Read(v)
+}
+
+func (d *s2Decoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *s2Decoder) Reset(r io.Reader) error {
+	d.reader.Reset(r)
+	return nil
+}
+
+func (d *s2Decoder) Close() error {
+	return nil
+}
+
+func init() {
+	compress.Register("s2", &s2Algorithm{})
+}
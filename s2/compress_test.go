@@ -0,0 +1,89 @@
+package s2
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.S2"); err != nil {
+		t.Error(err)
+	} else if name != "s2" {
+		t.Errorf("expected s2 got %s", name)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.DefaultCompression, compress.BestSpeed, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("s2", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{compress.HuffmanOnly, compress.NoCompression, 10} {
+		if _, err := compress.NewAlgorithm("s2", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	// Highly repetitive input compresses equally well in every mode, use words in random order.
+	words := []string{"alpha", "beta", "gamma", "delta", "compress", "stream", "block", "the", "fox", "dog"}
+	r := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	for buf.Len() < 500000 {
+		buf.WriteString(words[r.Intn(len(words))])
+		buf.WriteByte(' ')
+	}
+
+	sizes := map[compress.Level]int{}
+	for _, level := range []compress.Level{compress.BestSpeed, compress.BestCompression} {
+		a, err := compress.NewAlgorithm("s2", compress.WithLevel(level))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[level] = len(encoded)
+	}
+
+	if sizes[compress.BestCompression] >= sizes[compress.BestSpeed] {
+		t.Errorf("best compression (%d bytes) should be smaller than best speed (%d bytes)", sizes[compress.BestCompression], sizes[compress.BestSpeed])
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	// Several blocks so they are compressed in parallel.
+	var buf bytes.Buffer
+	for buf.Len() < 4<<20 {
+		fmt.Fprintf(&buf, "line %d: the quick brown fox jumps over the lazy dog\n", buf.Len())
+	}
+
+	a, err := compress.NewAlgorithm("s2", compress.WithConcurrency(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ioutil.ReadAll(s2.NewReader(bytes.NewReader(encoded))); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(buf.Bytes(), got) {
+		t.Error("single threaded decode doesn't match expected value")
+	}
+}
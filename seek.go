@@ -0,0 +1,212 @@
+package compress
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+var (
+	errWhence = errors.New("seek: invalid whence")
+	errOffset = errors.New("seek: invalid offset")
+)
+
+// seekable algorithms with random access to the decoded data.
+var seekable = map[string]bool{
+	"bgzf":  true,
+	"gzip":  true,
+	"store": true,
+}
+
+// encoderAtAlgorithm algorithm with an encoder writing to an io.WriterAt.
+type encoderAtAlgorithm interface {
+	NewEncoderAt(w io.WriterAt) (Encoder, error)
+}
+
+// NewEncoderAt algorithm encoding to w from offset 0, for formats where the encoder patches headers after
+// writing the body, so indexed output is written in one pass without buffering. Supported by bgzf which
+// writes each block directly and patches BSIZE, other algorithms return ErrWriterAtUnsupported.
+func NewEncoderAt(a Algorithm, w io.WriterAt) (Encoder, error) {
+	ea, ok := a.(encoderAtAlgorithm)
+	if !ok {
+		return nil, ErrWriterAtUnsupported
+	}
+	return ea.NewEncoderAt(w)
+}
+
+// member of a concatenated stream with the offset and size in the encoded and decoded data.
+type member struct {
+	offset int64
+	size   int64
+	start  int64
+	length int64
+}
+
+// byteCounter counts the bytes consumed, it implements io.ByteReader so decoders such as gzip don't read ahead.
+type byteCounter struct {
+	reader *bufio.Reader
+	n      int64
+}
+
+func (c *byteCounter) Read(v []byte) (int, error) {
+	n, err := c.reader.Read(v)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *byteCounter) ReadByte() (byte, error) {
+	b, err := c.reader.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+type seekableReader struct {
+	algorithm Algorithm
+	reader    io.ReaderAt
+	members   []member
+	size      int64
+	pos       int64
+
+	// decoder for the member at index, positioned at decoded.
+	decoder Decoder
+	index   int
+	decoded int64
+}
+
+// NewSeekableReader algorithm with random access to the decoded data of r, size is the length of r.
+// Seeking decodes from the start of the member containing the offset, so a stream of many small members,
+// such as bgzip or EncodeAppend output, seeks fast. The members are indexed by decoding r once.
+// Supported by bgzf, gzip and store, other algorithms return ErrSeekUnsupported.
+func NewSeekableReader(a Algorithm, r io.ReaderAt, size int64) (io.ReadSeeker, error) {
+	if !seekable[a.Name()] {
+		return nil, ErrSeekUnsupported
+	}
+
+	if a.Name() == "store" {
+		return io.NewSectionReader(r, 0, size), nil
+	}
+
+	// Checksum verify reads ahead of the member to find the trailer.
+	a = a.Clone()
+	if err := a.SetChecksumVerify(false); err != nil {
+		return nil, err
+	}
+
+	s := &seekableReader{algorithm: a, reader: r}
+	if err := s.indexMembers(size); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *seekableReader) indexMembers(size int64) error {
+	c := &byteCounter{reader: bufio.NewReader(io.NewSectionReader(s.reader, 0, size))}
+	d, err := s.algorithm.NewDecoder(c)
+	if err != nil {
+		return err
+	}
+
+	ms, ok := d.(multistreamDecoder)
+	if !ok {
+		_ = d.Close()
+		return ErrSeekUnsupported
+	}
+
+	var offset int64
+	for {
+		ms.Multistream(false)
+		n, err := io.Copy(ioutil.Discard, d)
+		if err != nil {
+			_ = d.Close()
+			return err
+		}
+
+		s.members = append(s.members, member{offset: offset, size: c.n - offset, start: s.size, length: n})
+		offset = c.n
+		s.size += n
+
+		if err := d.Reset(c); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return d.Close()
+}
+
+// seek decoder to the current position, reusing the open decoder when moving forward within the same member.
+func (s *seekableReader) seek() error {
+	i := sort.Search(len(s.members), func(i int) bool {
+		return s.members[i].start+s.members[i].length > s.pos
+	})
+	m := s.members[i]
+
+	if s.decoder == nil || i != s.index || s.pos < s.decoded {
+		if err := s.closeDecoder(); err != nil {
+			return err
+		}
+
+		d, err := s.algorithm.NewDecoder(io.NewSectionReader(s.reader, m.offset, m.size))
+		if err != nil {
+			return err
+		}
+		s.decoder, s.index, s.decoded = d, i, m.start
+	}
+
+	n, err := io.CopyN(ioutil.Discard, s.decoder, s.pos-s.decoded)
+	s.decoded += n
+	return err
+}
+
+func (s *seekableReader) closeDecoder() error {
+	if s.decoder == nil {
+		return nil
+	}
+	err := s.decoder.Close()
+	s.decoder = nil
+	return err
+}
+
+func (s *seekableReader) Read(v []byte) (int, error) {
+	if s.pos >= s.size {
+		return 0, io.EOF
+	}
+
+	if s.decoder == nil || s.pos != s.decoded {
+		if err := s.seek(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.decoder.Read(v)
+	s.pos += int64(n)
+	s.decoded = s.pos
+	if err == io.EOF {
+		// Continue with the next member on the next read.
+		return n, s.closeDecoder()
+	}
+	return n, err
+}
+
+func (s *seekableReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, errWhence
+	}
+
+	if offset < 0 {
+		return 0, errOffset
+	}
+	s.pos = offset
+	return offset, nil
+}
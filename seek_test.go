@@ -0,0 +1,132 @@
+package compress_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestNewSeekableReader(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exp, encoded []byte
+	for i := 0; i < 10; i++ {
+		v := []byte(fmt.Sprintf("%s%d\n", bytes.Repeat([]byte("abc123\ndef456\n"), 500), i))
+		if encoded, err = compress.EncodeAppend(a, encoded, v); err != nil {
+			t.Fatal(err)
+		}
+		exp = append(exp, v...)
+	}
+
+	r, err := compress.NewSeekableReader(a, bytes.NewReader(encoded), int64(len(encoded)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read across the boundary between two members.
+	offset := int64(len(exp)/2 - 500)
+	if n, err := r.Seek(offset, io.SeekStart); err != nil || n != offset {
+		t.Fatalf("seek expected %d got %d, %v", offset, n, err)
+	}
+
+	got := make([]byte, 1000)
+	if _, err := io.ReadFull(r, got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(exp[offset:offset+1000], got) {
+		t.Errorf("read at %d doesn't match expected value", offset)
+	}
+
+	if _, err := r.Seek(-10, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ioutil.ReadAll(r); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp[len(exp)-10:], got) {
+		t.Errorf("expected %q got %q", exp[len(exp)-10:], got)
+	}
+
+	z, err := compress.NewAlgorithm("zlib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := compress.NewSeekableReader(z, bytes.NewReader(nil), 0); err != compress.ErrSeekUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrSeekUnsupported, err)
+	}
+}
+
+func TestNewEncoderAt(t *testing.T) {
+	a, err := compress.NewAlgorithm("bgzf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&buf, "line %d\n", i)
+	}
+	exp := buf.Bytes()
+
+	f, err := ioutil.TempFile("", "compress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	e, err := compress.NewEncoderAt(a, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Write(exp); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same output as buffering each block.
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written, err := ioutil.ReadFile(f.Name()); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(encoded, written) {
+		t.Error("encode at doesn't match encode")
+	}
+
+	r, err := compress.NewSeekableReader(a, f, fi.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Seek(100000, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 100)
+	if _, err := io.ReadFull(r, got); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(exp[100000:100100], got) {
+		t.Errorf("expected %q got %q", exp[100000:100100], got)
+	}
+
+	if _, err := compress.NewEncoderAt(compress.MustNewAlgorithm("gzip"), f); err != compress.ErrWriterAtUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrWriterAtUnsupported, err)
+	}
+}
@@ -2,6 +2,7 @@ package snappy
 
 import (
 	"io"
+	"time"
 
 	"github.com/golang/snappy"
 	"github.com/pkg/errors"
@@ -9,10 +10,20 @@ import (
 	"github.com/mickep76/compress"
 )
 
-type snappyAlgorithm struct{}
+// levels snappy has no compression levels, only the default is accepted.
+var levels = []compress.Level{compress.DefaultCompression}
+
+type snappyAlgorithm struct {
+	compress.Options
+}
+
+// streamIdentifier first chunk of a stream, snappy only writes it with the first data.
+const streamIdentifier = "\xff\x06\x00\x00sNaPpY"
 
 type snappyEncoder struct {
-	writer *snappy.Writer
+	writer  *snappy.Writer
+	dst     io.Writer
+	written bool
 }
 
 type snappyDecoder struct {
@@ -23,24 +34,121 @@ func (a *snappyAlgorithm) NewAlgorithm() compress.Algorithm {
 	return &snappyAlgorithm{}
 }
 
+func (a *snappyAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *snappyAlgorithm) Name() string {
+	return "snappy"
+}
+
 func (a *snappyAlgorithm) Ext() string {
+	return "sz"
+}
+
+func (a *snappyAlgorithm) String() string {
 	return "snappy"
 }
 
+func (a *snappyAlgorithm) Capabilities() compress.Capability {
+	return compress.CapFlush | compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *snappyAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+func (a *snappyAlgorithm) DefaultLevel() compress.Level {
+	return compress.NoCompression
+}
+
 func (a *snappyAlgorithm) SetLevel(level compress.Level) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algoritha *snappy")
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm snappy level %d", level)
+	}
+	return nil
 }
 
 func (a *snappyAlgorithm) SetEndian(endian compress.Endian) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algoritha *snappy")
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm snappy")
 }
 
 func (a *snappyAlgorithm) SetLitWidth(width int) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algoritha *snappy")
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm snappy")
+}
+
+// SetDeterministic is a no-op, the output of snappy is always the same for the same input.
+func (a *snappyAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *snappyAlgorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+func (a *snappyAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return errors.Wrap(compress.ErrChecksumToggleUnsupported, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm snappy")
+}
+
+func (a *snappyAlgorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm snappy")
+}
+
+// SetMaxMemory is a no-op, blocks are at most 64KB.
+func (a *snappyAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm snappy max memory %d", n)
+	}
+	return nil
+}
+
+func (a *snappyAlgorithm) Validate() error {
+	return nil
 }
 
 func (a *snappyAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
-	return &snappyEncoder{writer: snappy.NewWriter(w)}, nil
+	return &snappyEncoder{writer: snappy.NewBufferedWriter(w), dst: w}, nil
 }
 
 func (a *snappyAlgorithm) Encode(v []byte) ([]byte, error) {
@@ -48,11 +156,38 @@ func (a *snappyAlgorithm) Encode(v []byte) ([]byte, error) {
 }
 
 func (e *snappyEncoder) Write(v []byte) (int, error) {
-	return e.writer.Write(v)
+	n, err := e.writer.Write(v)
+	e.written = e.written || n > 0
+	return n, err
+}
+
+func (e *snappyEncoder) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.Copy(e.writer, r)
+	e.written = e.written || n > 0
+	return n, err
+}
+
+func (e *snappyEncoder) Reset(w io.Writer) error {
+	e.writer.Reset(w)
+	e.dst = w
+	e.written = false
+	return nil
+}
+
+func (e *snappyEncoder) Flush() error {
+	return e.writer.Flush()
 }
 
 func (e *snappyEncoder) Close() error {
-	return e.writer.Close()
+	if err := e.writer.Close(); err != nil {
+		return err
+	}
+	if !e.written {
+		// An empty stream still has the identifier, so it can be detected and decoded.
+		_, err := io.WriteString(e.dst, streamIdentifier)
+		return err
+	}
+	return nil
 }
 
 func (a *snappyAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
@@ -67,6 +202,15 @@ func (d *snappyDecoder) Read(v []byte) (int, error) {
 	return d.reader.Read(v)
 }
 
+func (d *snappyDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *snappyDecoder) Reset(r io.Reader) error {
+	d.reader.Reset(r)
+	return nil
+}
+
 func (d *snappyDecoder) Close() error {
 	return nil
 }
@@ -0,0 +1,93 @@
+package snappy
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestSetLevel(t *testing.T) {
+	if _, err := compress.NewAlgorithm("snappy", compress.WithLevel(compress.DefaultCompression)); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := compress.NewAlgorithm("snappy", compress.WithLevel(compress.BestCompression)); err == nil {
+		t.Error("snappy should not support setting best compression")
+	}
+}
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.SZ"); err != nil {
+		t.Error(err)
+	} else if name != "snappy" {
+		t.Errorf("expected snappy got %s", name)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	if _, err := compress.NewAlgorithm("snappy", compress.WithName("abc.txt")); err == nil {
+		t.Error("snappy should not support header fields")
+	}
+}
+
+func TestDecodeWithHeader(t *testing.T) {
+	a, err := compress.NewAlgorithm("snappy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode([]byte("abc123\ndef456\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, h, err := compress.DecodeWithHeader(a, encoded); err != nil {
+		t.Error(err)
+	} else if h != nil {
+		t.Error("header should be nil for snappy")
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	if _, err := compress.NewAlgorithm("snappy", compress.WithDictionary([]byte("abc"))); errors.Cause(err) != compress.ErrDictionaryUnsupported {
+		t.Errorf("expected dictionary unsupported got: %v", err)
+	}
+}
+
+func TestDecodeMembers(t *testing.T) {
+	a, err := compress.NewAlgorithm("snappy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []byte("abc123\ndef456\n")
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	members, err := compress.DecodeMembers(a, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(members) != 1 || string(members[0]) != string(exp) {
+		t.Errorf("expected a single member got %q", members)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.DefaultCompression} {
+		if _, err := compress.NewAlgorithm("snappy", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{compress.NoCompression, compress.BestSpeed, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("snappy", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
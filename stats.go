@@ -0,0 +1,59 @@
+package compress
+
+import (
+	"time"
+)
+
+// Stats for an encode.
+type Stats struct {
+	OriginalSize   int
+	CompressedSize int
+
+	// Ratio compressed size divided by original size, 0 for empty input.
+	Ratio    float64
+	Duration time.Duration
+}
+
+// EncodeStats algorithm returning stats for the encode.
+func EncodeStats(a Algorithm, v []byte) ([]byte, Stats, error) {
+	start := time.Now()
+	encoded, err := Encode(a, v)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+
+	s := Stats{
+		OriginalSize:   len(v),
+		CompressedSize: len(encoded),
+		Duration:       time.Since(start),
+	}
+	if s.OriginalSize > 0 {
+		s.Ratio = float64(s.CompressedSize) / float64(s.OriginalSize)
+	}
+	return encoded, s, nil
+}
+
+// EncodeBest encodes with each candidate algorithm and returns the smallest output.
+// Ties prefer the earlier candidate.
+func EncodeBest(v []byte, candidates ...string) (name string, out []byte, err error) {
+	if len(candidates) == 0 {
+		return "", nil, ErrNoCandidates
+	}
+
+	for _, c := range candidates {
+		a, err := NewAlgorithm(c)
+		if err != nil {
+			return "", nil, err
+		}
+
+		encoded, err := a.Encode(v)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if out == nil || len(encoded) < len(out) {
+			name, out = c, encoded
+		}
+	}
+	return name, out, nil
+}
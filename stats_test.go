@@ -0,0 +1,39 @@
+package compress_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeStats(t *testing.T) {
+	a, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 100)
+	encoded, s, err := compress.EncodeStats(a, exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.OriginalSize != len(exp) || s.CompressedSize != len(encoded) {
+		t.Errorf("unexpected sizes: %+v", s)
+	}
+
+	if s.Ratio != float64(len(encoded))/float64(len(exp)) || s.Ratio >= 1 {
+		t.Errorf("unexpected ratio: %f", s.Ratio)
+	}
+
+	if s.Duration < 0 {
+		t.Errorf("unexpected duration: %s", s.Duration)
+	}
+
+	if _, s, err := compress.EncodeStats(a, []byte{}); err != nil {
+		t.Error(err)
+	} else if s.Ratio != 0 {
+		t.Errorf("expected ratio 0 for empty input got %f", s.Ratio)
+	}
+}
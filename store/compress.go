@@ -0,0 +1,197 @@
+package store
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+type storeAlgorithm struct {
+	compress.Options
+}
+
+type storeEncoder struct {
+	writer io.Writer
+}
+
+type storeDecoder struct {
+	reader io.Reader
+}
+
+func (a *storeAlgorithm) NewAlgorithm() compress.Algorithm {
+	return &storeAlgorithm{}
+}
+
+func (a *storeAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *storeAlgorithm) Name() string {
+	return "store"
+}
+
+func (a *storeAlgorithm) Ext() string {
+	return ""
+}
+
+func (a *storeAlgorithm) String() string {
+	return "store"
+}
+
+func (a *storeAlgorithm) Capabilities() compress.Capability {
+	return compress.CapFlush | compress.CapMultistream
+}
+
+func (a *storeAlgorithm) ValidLevels() []compress.Level {
+	return nil
+}
+
+func (a *storeAlgorithm) DefaultLevel() compress.Level {
+	return compress.NoCompression
+}
+
+// SetLevel any level is accepted and ignored, the data is always stored.
+func (a *storeAlgorithm) SetLevel(level compress.Level) error {
+	return nil
+}
+
+func (a *storeAlgorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm store")
+}
+
+// SetLitWidth any lit width is accepted and ignored.
+func (a *storeAlgorithm) SetLitWidth(width int) error {
+	return nil
+}
+
+func (a *storeAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm store")
+}
+
+// SetDeterministic is a no-op, the output of store is always the same for the same input.
+func (a *storeAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *storeAlgorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+// SetNoChecksum is a no-op, store has no checksum.
+func (a *storeAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return nil
+}
+
+func (a *storeAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm store")
+}
+
+func (a *storeAlgorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm store")
+}
+
+// SetMaxMemory is a no-op, there are no buffers.
+func (a *storeAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm store max memory %d", n)
+	}
+	return nil
+}
+
+func (a *storeAlgorithm) Validate() error {
+	return nil
+}
+
+func (a *storeAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	return &storeEncoder{writer: w}, nil
+}
+
+func (a *storeAlgorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+func (e *storeEncoder) Write(v []byte) (int, error) {
+	return e.writer.Write(v)
+}
+
+func (e *storeEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
+}
+
+func (e *storeEncoder) Reset(w io.Writer) error {
+	e.writer = w
+	return nil
+}
+
+func (e *storeEncoder) Flush() error {
+	return nil
+}
+
+func (e *storeEncoder) Close() error {
+	return nil
+}
+
+func (a *storeAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	return &storeDecoder{reader: r}, nil
+}
+
+func (a *storeAlgorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *storeDecoder) Read(v []byte) (int, error) {
+	return d.reader.Read(v)
+}
+
+func (d *storeDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *storeDecoder) Reset(r io.Reader) error {
+	d.reader = r
+	return nil
+}
+
+func (d *storeDecoder) Close() error {
+	return nil
+}
+
+func init() {
+	compress.Register("store", &storeAlgorithm{})
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestIdentity(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	a, err := compress.NewAlgorithm("store", compress.WithLevel(compress.BestCompression), compress.WithLitWidth(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(encoded) != len(exp) || !bytes.Equal(exp, encoded) {
+		t.Errorf("expected encode to store %d bytes unchanged got %d bytes", len(exp), len(encoded))
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+}
+
+func TestExtensions(t *testing.T) {
+	if _, ok := compress.Extensions()[""]; ok {
+		t.Error("store has no extension and shouldn't be listed")
+	}
+
+	if _, err := compress.DetectByExt("abc"); err == nil {
+		t.Error("a file without extension shouldn't be detected as store")
+	}
+}
@@ -0,0 +1,72 @@
+package compress
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+)
+
+// EncodeStream using the named algorithm, encoding r to w.
+func EncodeStream(name string, r io.Reader, w io.Writer, opts ...Option) error {
+	a, err := NewAlgorithm(name, opts...)
+	if err != nil {
+		return err
+	}
+	return encodeTo(a, w, withProgress(a, r))
+}
+
+// DecodeStream using the named algorithm, decoding r to w.
+func DecodeStream(name string, r io.Reader, w io.Writer) error {
+	a, err := NewAlgorithm(name)
+	if err != nil {
+		return err
+	}
+
+	d, err := a.NewDecoder(r)
+	if err != nil {
+		return err
+	}
+
+	if err := copyTimeout(a, w, d); err != nil {
+		_ = d.Close()
+		return err
+	}
+
+	return d.Close()
+}
+
+// EncodePipe using the named algorithm, encoding stdin to stdout with buffered IO, for tools like mygzip < in > out.
+// Stdout being closed by the reader, i.e. piped to head, ends encoding without an error.
+func EncodePipe(name string, opts ...Option) error {
+	a, err := NewAlgorithm(name, opts...)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriterSize(os.Stdout, bufferSize(a))
+	if err := encodeTo(a, w, withProgress(a, bufio.NewReaderSize(os.Stdin, bufferSize(a)))); err != nil {
+		return pipeErr(err)
+	}
+	return pipeErr(w.Flush())
+}
+
+// DecodePipe using the named algorithm, decoding stdin to stdout with buffered IO.
+// Stdout being closed by the reader ends decoding without an error.
+func DecodePipe(name string) error {
+	w := bufio.NewWriterSize(os.Stdout, DefaultBufferSize)
+	if err := DecodeStream(name, bufio.NewReaderSize(os.Stdin, DefaultBufferSize), w); err != nil {
+		return pipeErr(err)
+	}
+	return pipeErr(w.Flush())
+}
+
+// pipeErr ignores a broken pipe, the reader of the output is done. When stdout is a pipe the process
+// gets SIGPIPE instead, unless it's ignored, and exits quietly same as gzip.
+func pipeErr(err error) error {
+	if errors.Is(err, syscall.EPIPE) {
+		return nil
+	}
+	return err
+}
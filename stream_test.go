@@ -0,0 +1,113 @@
+package compress_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeDecodeStream(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	var encoded bytes.Buffer
+	if err := compress.EncodeStream("gzip", bytes.NewReader(exp), &encoded, compress.WithLevel(compress.BestCompression)); err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+	if err := compress.DecodeStream("gzip", &encoded, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(exp, got.Bytes()) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	if err := compress.EncodeStream("foo", bytes.NewReader(exp), &encoded); err == nil {
+		t.Error("expected an error for an unregistered algorithm")
+	}
+}
+
+// withPipes substitutes stdin and stdout with pipes, writing in to stdin and returning what's written to stdout.
+func withPipes(t *testing.T, in []byte, fn func() error) []byte {
+	stdin, stdout := os.Stdin, os.Stdout
+	defer func() { os.Stdin, os.Stdout = stdin, stdout }()
+
+	inr, inw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outr, outw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdin, os.Stdout = inr, outw
+
+	go func() {
+		_, _ = inw.Write(in)
+		_ = inw.Close()
+	}()
+	out := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(outr)
+		out <- b
+	}()
+
+	if err := fn(); err != nil {
+		t.Fatal(err)
+	}
+	_ = outw.Close()
+	_ = inr.Close()
+	return <-out
+}
+
+func TestEncodeDecodePipe(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 10000)
+
+	encoded := withPipes(t, exp, func() error {
+		return compress.EncodePipe("gzip", compress.WithLevel(compress.BestSpeed))
+	})
+	got := withPipes(t, encoded, func() error {
+		return compress.DecodePipe("gzip")
+	})
+
+	if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+}
+
+func TestDecodePipeClosed(t *testing.T) {
+	encoded, err := compress.MustNewAlgorithm("gzip").Encode(bytes.Repeat([]byte("abc123\ndef456\n"), 100000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdin, stdout := os.Stdin, os.Stdout
+	defer func() { os.Stdin, os.Stdout = stdin, stdout }()
+
+	inr, inw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outr, outw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inr.Close()
+	defer outw.Close()
+	os.Stdin, os.Stdout = inr, outw
+
+	go func() {
+		_, _ = inw.Write(encoded)
+		_ = inw.Close()
+	}()
+
+	// The reader of stdout is done.
+	_ = outr.Close()
+	if err := compress.DecodePipe("gzip"); err != nil {
+		t.Errorf("expected a closed stdout to end decoding quietly got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package compress
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// Encoding textual wrapper of encoded data, such as for JSON.
+type Encoding int
+
+const (
+	// Base64 standard encoding with padding, RFC 4648.
+	Base64 Encoding = iota
+
+	// Hex lower case.
+	Hex
+)
+
+// EncodeEncoded algorithm returning the encoded data wrapped as text with enc.
+func EncodeEncoded(a Algorithm, v []byte, enc Encoding) (string, error) {
+	if enc != Base64 && enc != Hex {
+		return "", fmt.Errorf("%w: %d", ErrInvalidEncoding, enc)
+	}
+
+	encoded, err := Encode(a, v)
+	if err != nil {
+		return "", err
+	}
+
+	if enc == Hex {
+		return hex.EncodeToString(encoded), nil
+	}
+	return base64.StdEncoding.EncodeToString(encoded), nil
+}
+
+// DecodeEncoded algorithm unwrapping the text s with enc and decoding it, i.e. base64 gzip in JSON.
+func DecodeEncoded(a Algorithm, s string, enc Encoding) ([]byte, error) {
+	var v []byte
+	var err error
+	switch enc {
+	case Base64:
+		v, err = base64.StdEncoding.DecodeString(s)
+	case Hex:
+		v, err = hex.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrInvalidEncoding, enc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Decode(a, v)
+}
@@ -0,0 +1,47 @@
+package compress_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+func TestEncodeDecodeEncoded(t *testing.T) {
+	a := compress.MustNewAlgorithm("gzip")
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 100)
+
+	for _, enc := range []compress.Encoding{compress.Base64, compress.Hex} {
+		s, err := compress.EncodeEncoded(a, exp, enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := compress.DecodeEncoded(a, s, enc); err != nil {
+			t.Errorf("encoding %d: %v", enc, err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("encoding %d: decode doesn't match expected value", enc)
+		}
+	}
+
+	// Same as a payload encoded in two steps.
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := compress.DecodeEncoded(a, base64.StdEncoding.EncodeToString(encoded), compress.Base64); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	if _, err := compress.DecodeEncoded(a, "not hex", compress.Hex); err == nil {
+		t.Error("expected an error for invalid hex")
+	}
+	if _, err := compress.EncodeEncoded(a, exp, compress.Encoding(5)); !errors.Is(err, compress.ErrInvalidEncoding) {
+		t.Errorf("expected %v got %v", compress.ErrInvalidEncoding, err)
+	}
+}
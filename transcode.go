@@ -0,0 +1,26 @@
+package compress
+
+import (
+	"bytes"
+)
+
+// Transcode v decoding with from and encoding with to, streaming the decoded data between them
+// a buffer at a time.
+func Transcode(from, to Algorithm, v []byte) ([]byte, error) {
+	d, err := from.NewDecoder(bytes.NewReader(v))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := encodeTo(to, &buf, d); err != nil {
+		_ = d.Close()
+		return nil, err
+	}
+
+	if err := d.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
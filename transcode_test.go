@@ -0,0 +1,43 @@
+package compress_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestTranscode(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	from, err := compress.NewAlgorithm("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := compress.NewAlgorithm("zstd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := from.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transcoded, err := compress.Transcode(from, to, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := to.Decode(transcoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+
+	if _, err := compress.Transcode(from, to, encoded[:len(encoded)/2]); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v got %v", io.ErrUnexpectedEOF, err)
+	}
+}
@@ -0,0 +1,82 @@
+package compress
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecompressTransport http.RoundTripper advertising all registered algorithms with a content encoding,
+// responses are transparently decoded. If the request already sets Accept-Encoding it's left as is.
+type DecompressTransport struct {
+	// Base transport, defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *DecompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		if encs := acceptEncodingHeader(); encs != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("Accept-Encoding", encs)
+		}
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := resp.Header.Get("Content-Encoding")
+	if enc == "" || req.Method == "HEAD" || resp.Body == http.NoBody || resp.ContentLength == 0 {
+		return resp, nil
+	}
+
+	name, err := FromContentEncoding(enc)
+	if err != nil {
+		return resp, nil
+	}
+
+	d, err := NewDecoder(name, resp.Body)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	resp.Body = &decodedBody{Decoder: d, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// acceptEncodingHeader content encodings of the registered algorithms.
+func acceptEncodingHeader() string {
+	encs := []string{}
+	for _, name := range Algorithms() {
+		if enc := ToContentEncoding(name); enc != "" && enc != "identity" {
+			encs = append(encs, enc)
+		}
+	}
+	return strings.Join(encs, ", ")
+}
+
+// decodedBody closes both the decoder and the response body.
+type decodedBody struct {
+	Decoder
+	body io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	err := b.Decoder.Close()
+	if cerr := b.body.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package compress_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mickep76/compress"
+)
+
+func TestDecompressTransport(t *testing.T) {
+	body := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, enc := range []string{"gzip", "zstd"} {
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), enc) {
+				t.Errorf("accept encoding should include %s got %q", enc, r.Header.Get("Accept-Encoding"))
+			}
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		encoded, err := compress.MustNewAlgorithm(name).Encode(body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Encoding", compress.ToContentEncoding(name))
+		_, _ = w.Write(encoded)
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &compress.DecompressTransport{}}
+	for _, name := range []string{"gzip", "zstd"} {
+		resp, err := c.Get(srv.URL + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		_ = resp.Body.Close()
+
+		if !bytes.Equal(body, got) {
+			t.Errorf("%s response doesn't match expected value", name)
+		}
+
+		if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "" {
+			t.Errorf("%s content encoding and length should be removed", name)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package xz
 
 import (
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ulikunitz/xz"
@@ -9,10 +11,38 @@ import (
 	"github.com/mickep76/compress"
 )
 
-type xzAlgorithm struct{}
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.DefaultCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
+// dictCaps dictionary capacity matching the xz CLI presets -1 to -9.
+var dictCaps = map[compress.Level]int{
+	compress.DefaultCompression: 8 << 20,
+	compress.BestSpeed:          1 << 20,
+	2:                           2 << 20,
+	3:                           4 << 20,
+	4:                           4 << 20,
+	5:                           8 << 20,
+	6:                           8 << 20,
+	7:                           16 << 20,
+	8:                           32 << 20,
+	compress.BestCompression:    64 << 20,
+}
+
+type xzAlgorithm struct {
+	compress.Options
+
+	level      compress.Level
+	blockSize  int
+	noChecksum bool
+}
 
 type xzEncoder struct {
 	writer *xz.Writer
+	config xz.WriterConfig
 }
 
 type xzDecoder struct {
@@ -20,29 +50,134 @@ type xzDecoder struct {
 }
 
 func (a *xzAlgorithm) NewAlgorithm() compress.Algorithm {
-	return &xzAlgorithm{}
+	return &xzAlgorithm{level: compress.DefaultCompression}
+}
+
+func (a *xzAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	return &c
+}
+
+func (a *xzAlgorithm) Name() string {
+	return "xz"
 }
 
 func (a *xzAlgorithm) Ext() string {
 	return "xz"
 }
 
+func (a *xzAlgorithm) String() string {
+	return fmt.Sprintf("xz(level=%d, block=%d)", a.level, a.blockSize)
+}
+
+func (a *xzAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *xzAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+// DefaultLevel the xz CLI preset 6.
+func (a *xzAlgorithm) DefaultLevel() compress.Level {
+	return 6
+}
+
 func (a *xzAlgorithm) SetLevel(level compress.Level) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm xz")
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm xz level %d", level)
+	}
+	a.level = level
+	return nil
 }
 
 func (a *xzAlgorithm) SetEndian(endian compress.Endian) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm xz")
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm xz")
 }
 
 func (a *xzAlgorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm xz")
+}
+
+// SetDeterministic is a no-op, the output of xz is always the same for the same input.
+func (a *xzAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *xzAlgorithm) SetDictionary(dict []byte) error {
+	return errors.Wrap(compress.ErrDictionaryUnsupported, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetChecksumVerify(verify bool) error {
+	return nil
+}
+
+func (a *xzAlgorithm) SetNoChecksum(noChecksum bool) error {
+	a.noChecksum = noChecksum
+	return nil
+}
+
+func (a *xzAlgorithm) SetTrustSize(trust bool) error {
 	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm xz")
 }
 
+func (a *xzAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm xz")
+}
+
+func (a *xzAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm xz")
+}
+
+// SetBlockSize maximum uncompressed size of a block, by default everything is written as a single block.
+func (a *xzAlgorithm) SetBlockSize(n int) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidBlockSize, "algorithm xz block size %d", n)
+	}
+	a.blockSize = n
+	return nil
+}
+
+// SetMaxMemory is a no-op, the dictionary size is only known once a block is read.
+func (a *xzAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm xz max memory %d", n)
+	}
+	return nil
+}
+
+func (a *xzAlgorithm) Validate() error {
+	return nil
+}
+
 func (a *xzAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
-	e := &xzEncoder{}
+	e := &xzEncoder{config: xz.WriterConfig{DictCap: dictCaps[a.level], BlockSize: int64(a.blockSize), NoCheckSum: a.noChecksum}}
 	var err error
-	if e.writer, err = xz.NewWriter(w); err != nil {
+	if e.writer, err = e.config.NewWriter(w); err != nil {
 		return nil, err
 	}
 	return e, nil
@@ -56,6 +191,20 @@ func (e *xzEncoder) Write(v []byte) (int, error) {
 	return e.writer.Write(v)
 }
 
+func (e *xzEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
+}
+
+func (e *xzEncoder) Reset(w io.Writer) error {
+	var err error
+	e.writer, err = e.config.NewWriter(w)
+	return err
+}
+
+func (e *xzEncoder) Flush() error {
+	return errors.Wrap(compress.ErrFlushUnsupported, "algorithm xz")
+}
+
 func (e *xzEncoder) Close() error {
 	return e.writer.Close()
 }
@@ -77,6 +226,16 @@ func (d *xzDecoder) Read(v []byte) (int, error) {
 	return d.reader.Read(v)
 }
 
+func (d *xzDecoder) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, d.reader)
+}
+
+func (d *xzDecoder) Reset(r io.Reader) error {
+	var err error
+	d.reader, err = xz.NewReader(r)
+	return err
+}
+
 func (d *xzDecoder) Close() error {
 	return nil
 }
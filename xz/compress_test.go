@@ -2,9 +2,11 @@ package xz
 
 import (
 	"bytes"
-	"errors"
+	"io/ioutil"
 	"testing"
 
+	"github.com/pkg/errors"
+
 	"github.com/mickep76/compress"
 )
 
@@ -20,12 +22,84 @@ func TestNewEncoder(t *testing.T) {
 	}
 
 	if _, err := compress.NewEncoder("xz", &MockWriter{}); err == nil {
-		t.Error("this should not fail")
+		t.Error("a failing writer should fail writing the stream header")
 	}
 }
 
 func TestNewDecoder(t *testing.T) {
-	if _, err := compress.NewDecoder("gzip", &bytes.Buffer{}); err == nil {
+	if _, err := compress.NewDecoder("xz", &bytes.Buffer{}); err == nil {
 		t.Error("this should have generated a EOF error")
 	}
 }
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.XZ"); err != nil {
+		t.Error(err)
+	} else if name != "xz" {
+		t.Errorf("expected xz got %s", name)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.DefaultCompression, compress.BestSpeed, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("xz", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{compress.HuffmanOnly, compress.NoCompression, 10} {
+		if _, err := compress.NewAlgorithm("xz", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
+
+// testdata/abc.txt.xz was produced by the xz CLI (xz -6, CRC64 check).
+func TestDecodeFixture(t *testing.T) {
+	exp, err := ioutil.ReadFile("testdata/abc.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := ioutil.ReadFile("testdata/abc.txt.xz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := compress.NewAlgorithm("xz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded fixture doesn't match expected value")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+	for _, level := range []compress.Level{compress.BestSpeed, compress.DefaultCompression} {
+		a, err := compress.NewAlgorithm("xz", compress.WithLevel(level))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Stream header magic and footer magic.
+		if !bytes.HasPrefix(encoded, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}) || !bytes.HasSuffix(encoded, []byte("YZ")) {
+			t.Errorf("level %d output isn't a valid xz stream", level)
+		}
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("level %d decode doesn't match expected value", level)
+		}
+	}
+}
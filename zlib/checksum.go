@@ -0,0 +1,13 @@
+package zlib
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/mickep76/compress/internal/trailer"
+)
+
+// newTrailerReader keeps the last 4 bytes read, which is the zlib trailer (Adler-32) when the stream ends.
+func newTrailerReader(r io.Reader) *trailer.Reader {
+	return trailer.NewReader(r, 4, binary.BigEndian.Uint32)
+}
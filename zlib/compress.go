@@ -2,55 +2,191 @@ package zlib
 
 import (
 	"compress/zlib"
+	"fmt"
+	"hash"
+	"hash/adler32"
 	"io"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/mickep76/compress"
+	"github.com/mickep76/compress/internal/trailer"
 )
 
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.HuffmanOnly,
+	compress.DefaultCompression,
+	compress.NoCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
 type zlibAlgorithm struct {
-	level compress.Level
+	compress.Options
+
+	level     compress.Level
+	dict      []byte
+	verify    bool
+	flushMode compress.FlushMode
 }
 
 type zlibEncoder struct {
-	writer io.WriteCloser
+	writer    writer
+	flushMode compress.FlushMode
 }
 
 type zlibDecoder struct {
 	reader io.ReadCloser
+	dict   []byte
+
+	verify  bool
+	trailer *trailer.Reader
+	adler   hash.Hash32
 }
 
 func (a *zlibAlgorithm) NewAlgorithm() compress.Algorithm {
-	return &zlibAlgorithm{}
+	return &zlibAlgorithm{level: compress.DefaultCompression}
+}
+
+func (a *zlibAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	c.dict = append([]byte(nil), a.dict...)
+	return &c
+}
+
+func (a *zlibAlgorithm) Name() string {
+	return "zlib"
 }
 
 func (a *zlibAlgorithm) Ext() string {
-	return "gz"
+	return "zz"
+}
+
+func (a *zlibAlgorithm) String() string {
+	return fmt.Sprintf("zlib(level=%d, dict=%d)", a.level, len(a.dict))
+}
+
+func (a *zlibAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapDictionary | compress.CapFlush | compress.CapChecksum
+}
+
+func (a *zlibAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+func (a *zlibAlgorithm) DefaultLevel() compress.Level {
+	return compress.DefaultCompression
 }
 
 func (a *zlibAlgorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm zlib level %d", level)
+	}
 	a.level = level
 	return nil
 }
 
 func (a *zlibAlgorithm) SetEndian(endian compress.Endian) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm gzip")
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm zlib")
 }
 
 func (a *zlibAlgorithm) SetLitWidth(width int) error {
-	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm gzip")
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetWindow(bits int) error {
+	return errors.Wrap(compress.ErrWindowSizeUnsupported, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm zlib")
+}
+
+// SetDeterministic is a no-op, the output of zlib is always the same for the same input.
+func (a *zlibAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+func (a *zlibAlgorithm) SetDictionary(dict []byte) error {
+	a.dict = dict
+	return nil
+}
+
+func (a *zlibAlgorithm) SetChecksumVerify(verify bool) error {
+	a.verify = verify
+	return nil
+}
+
+func (a *zlibAlgorithm) SetNoChecksum(noChecksum bool) error {
+	return errors.Wrap(compress.ErrChecksumToggleUnsupported, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	switch mode {
+	case compress.SyncFlush, compress.NoFlush, compress.FullFlush:
+		a.flushMode = mode
+		return nil
+	}
+	return errors.Wrapf(compress.ErrUnsupportedOption, "algorithm zlib flush mode %d", mode)
+}
+
+func (a *zlibAlgorithm) SetConcurrency(n int) error {
+	return errors.Wrap(compress.ErrConcurrencyUnsupported, "algorithm zlib")
+}
+
+func (a *zlibAlgorithm) SetBlockSize(n int) error {
+	return errors.Wrap(compress.ErrBlockSizeUnsupported, "algorithm zlib")
+}
+
+// SetMaxMemory is a no-op, the window is 32KB.
+func (a *zlibAlgorithm) SetMaxMemory(n int64) error {
+	if n <= 0 {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm zlib max memory %d", n)
+	}
+	return nil
+}
+
+// Validate the dictionary isn't used without compression, flate ignores it for NoCompression and HuffmanOnly.
+func (a *zlibAlgorithm) Validate() error {
+	if len(a.dict) > 0 && (a.level == compress.NoCompression || a.level == compress.HuffmanOnly) {
+		return errors.Wrapf(compress.ErrConflictingOptions, "algorithm zlib dictionary is ignored at level %d", a.level)
+	}
+	return nil
 }
 
 func (a *zlibAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
-	e := &zlibEncoder{}
-	if a.level == 0 {
-		e.writer = zlib.NewWriter(w)
-	} else {
-		var err error
-		if e.writer, err = zlib.NewWriterLevel(w, int(a.level)); err != nil {
+	e := &zlibEncoder{flushMode: a.flushMode}
+	var err error
+	if a.flushMode == compress.FullFlush {
+		if e.writer, err = newFullFlushWriter(w, int(a.level), a.dict); err != nil {
 			return nil, err
 		}
+		return e, nil
+	}
+	if e.writer, err = zlib.NewWriterLevelDict(w, int(a.level), a.dict); err != nil {
+		return nil, err
 	}
 	return e, nil
 }
@@ -63,25 +199,84 @@ func (e *zlibEncoder) Write(v []byte) (int, error) {
 	return e.writer.Write(v)
 }
 
+func (e *zlibEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(e.writer, r)
+}
+
+func (e *zlibEncoder) Reset(w io.Writer) error {
+	e.writer.Reset(w)
+	return nil
+}
+
+func (e *zlibEncoder) Flush() error {
+	if e.flushMode == compress.NoFlush {
+		return nil
+	}
+	return e.writer.Flush()
+}
+
 func (e *zlibEncoder) Close() error {
 	return e.writer.Close()
 }
 
 func (a *zlibAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
-	d := &zlibDecoder{}
+	d := &zlibDecoder{dict: a.dict, verify: a.verify}
+	if d.verify {
+		d.trailer = newTrailerReader(r)
+		d.adler = adler32.New()
+		r = d.trailer
+	}
+
 	var err error
-	if d.reader, err = zlib.NewReader(r); err != nil {
-		return nil, err
+	if d.reader, err = zlib.NewReaderDict(r, a.dict); err != nil {
+		return nil, dictionaryError(a.dict, err)
 	}
 	return d, nil
 }
 
+// dictionaryError the DICTID in the header doesn't match the Adler32 of the dictionary, or none was set.
+func dictionaryError(dict []byte, err error) error {
+	switch {
+	case err != zlib.ErrDictionary:
+		return err
+	case dict == nil:
+		return errors.Wrap(compress.ErrDictionaryMismatch, "algorithm zlib stream requires a dictionary")
+	}
+	return errors.Wrapf(compress.ErrDictionaryMismatch, "algorithm zlib dictionary adler32 %08x doesn't match the stream", adler32.Checksum(dict))
+}
+
 func (a *zlibAlgorithm) Decode(v []byte) ([]byte, error) {
 	return compress.Decode(a, v)
 }
 
 func (d *zlibDecoder) Read(v []byte) (int, error) {
-	return d.reader.Read(v)
+	n, err := d.reader.Read(v)
+	if !d.verify {
+		return n, err
+	}
+
+	_, _ = d.adler.Write(v[:n])
+	if err == zlib.ErrChecksum {
+		return n, &compress.ChecksumError{Expected: d.trailer.Checksum(), Actual: d.adler.Sum32()}
+	}
+	return n, err
+}
+
+func (d *zlibDecoder) WriteTo(w io.Writer) (int64, error) {
+	if d.verify {
+		// Go through Read which checks the checksum.
+		return io.Copy(w, struct{ io.Reader }{d})
+	}
+	return io.Copy(w, d.reader)
+}
+
+func (d *zlibDecoder) Reset(r io.Reader) error {
+	if d.verify {
+		d.trailer = newTrailerReader(r)
+		d.adler.Reset()
+		r = d.trailer
+	}
+	return dictionaryError(d.dict, d.reader.(zlib.Resetter).Reset(r, d.dict))
 }
 
 func (d *zlibDecoder) Close() error {
@@ -2,8 +2,12 @@ package zlib
 
 import (
 	"bytes"
+	"hash/adler32"
+	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
+
 	"github.com/mickep76/compress"
 )
 
@@ -12,3 +16,184 @@ func TestNewDecoder(t *testing.T) {
 		t.Error("this should have generated a EOF error")
 	}
 }
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.ZZ"); err != nil {
+		t.Error(err)
+	} else if name != "zlib" {
+		t.Errorf("expected zlib got %s", name)
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	dict := []byte(`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 0, "name": "", "email": "", "verified": false, "created": "2018-06-01T12:00:00Z"}}`)
+	exp := []byte(`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 42, "name": "abc", "email": "abc@example.com", "verified": true, "created": "2018-06-01T12:00:00Z"}}`)
+
+	a, err := compress.NewAlgorithm("zlib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := compress.NewAlgorithm("zlib", compress.WithDictionary(dict))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := d.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(encoded) >= len(plain) {
+		t.Errorf("dictionary encoded (%d bytes) should be smaller than without dictionary (%d bytes)", len(encoded), len(plain))
+	}
+
+	if got, err := d.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decoded output doesn't match original")
+	}
+
+	if got, err := a.Decode(encoded); err == nil && bytes.Equal(exp, got) {
+		t.Error("decoding without the dictionary should fail")
+	}
+}
+
+func TestDictionaryMismatch(t *testing.T) {
+	a, err := compress.NewAlgorithm("zlib", compress.WithDictionary([]byte("abcdef")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := a.Encode([]byte("abcdef123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := compress.MustNewAlgorithm("zlib").Encode([]byte("abcdef123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range [][]compress.Option{{compress.WithDictionary([]byte("ghijkl"))}, nil} {
+		b, err := compress.NewAlgorithm("zlib", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := b.Decode(encoded); errors.Cause(err) != compress.ErrDictionaryMismatch {
+			t.Errorf("expected %v got %v", compress.ErrDictionaryMismatch, err)
+		}
+
+		// Reset checks the next stream as well.
+		d, err := b.NewDecoder(bytes.NewReader(plain))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Reset(bytes.NewReader(encoded)); errors.Cause(err) != compress.ErrDictionaryMismatch {
+			t.Errorf("reset expected %v got %v", compress.ErrDictionaryMismatch, err)
+		}
+	}
+}
+
+func TestChecksumVerify(t *testing.T) {
+	a, err := compress.NewAlgorithm("zlib", compress.WithChecksumVerify(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []byte("abc123\ndef456\n")
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded[len(encoded)-1] ^= 0xff
+	_, err = a.Decode(encoded)
+	if errors.Cause(err) != compress.ErrChecksumMismatch {
+		t.Fatalf("expected checksum mismatch got: %v", err)
+	}
+
+	if cerr, ok := err.(*compress.ChecksumError); !ok {
+		t.Errorf("expected *compress.ChecksumError got %T", err)
+	} else if cerr.Actual != adler32.Checksum(exp) || cerr.Expected != cerr.Actual^0xff {
+		t.Errorf("unexpected checksum values: %s", cerr)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.HuffmanOnly, compress.NoCompression, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("zlib", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{-3, 10} {
+		if _, err := compress.NewAlgorithm("zlib", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dict := []byte("abc123\ndef456\n")
+
+	for _, level := range []compress.Level{compress.NoCompression, compress.HuffmanOnly} {
+		if _, err := compress.NewAlgorithm("zlib", compress.WithDictionary(dict), compress.WithLevel(level)); errors.Cause(err) != compress.ErrConflictingOptions {
+			t.Errorf("dictionary with level %d expected %v got %v", level, compress.ErrConflictingOptions, err)
+		}
+	}
+
+	if _, err := compress.NewAlgorithm("zlib", compress.WithDictionary(dict), compress.WithLevel(compress.BestSpeed)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFullFlushDictionary(t *testing.T) {
+	dict := []byte(`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 0, "name": "", "email": ""}}`)
+	parts := []string{
+		`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 1, "name": "abc", "email": ""}}`,
+		`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 2, "name": "def", "email": ""}}`,
+		`{"type": "user.created", "version": 1, "source": "accounts", "data": {"id": 3, "name": "ghi", "email": ""}}`,
+	}
+
+	a, err := compress.NewAlgorithm("zlib", compress.WithDictionary(dict), compress.WithFlushMode(compress.FullFlush))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	e, err := a.NewEncoder(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reset after a full flush starts the next stream with the dictionary again.
+	for i := 0; i < 2; i++ {
+		buf.Reset()
+		if err := e.Reset(buf); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range parts {
+			if _, err := e.Write([]byte(p)); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := a.Decode(buf.Bytes()); err != nil {
+			t.Error(err)
+		} else if exp := strings.Join(parts, ""); string(got) != exp {
+			t.Errorf("expected %q got %q", exp, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package zlib
+
+import (
+	"compress/flate"
+	"encoding/binary"
+	"hash"
+	"hash/adler32"
+	"io"
+)
+
+// writer implemented by zlib.Writer and fullFlushWriter.
+type writer interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// fullFlushWriter writes the zlib header and trailer itself around a flate writer, so the compressor can be
+// reset on Flush which zlib.Writer doesn't support.
+type fullFlushWriter struct {
+	dst         io.Writer
+	level       int
+	dict        []byte
+	writer      *flate.Writer
+	adler       hash.Hash32
+	wroteHeader bool
+
+	// start writer primed with the dictionary, after Flush plain without it is used until Reset.
+	start *flate.Writer
+	plain *flate.Writer
+}
+
+func newFullFlushWriter(w io.Writer, level int, dict []byte) (*fullFlushWriter, error) {
+	fw, err := flate.NewWriterDict(w, level, dict)
+	if err != nil {
+		return nil, err
+	}
+	return &fullFlushWriter{dst: w, level: level, dict: dict, writer: fw, adler: adler32.New(), start: fw}, nil
+}
+
+// writeHeader same as compress/zlib.
+func (z *fullFlushWriter) writeHeader() error {
+	z.wroteHeader = true
+	h := []byte{0x78, 0}
+	switch z.level {
+	case -2, 0, 1:
+		h[1] = 0 << 6
+	case 2, 3, 4, 5:
+		h[1] = 1 << 6
+	case 6, -1:
+		h[1] = 2 << 6
+	case 7, 8, 9:
+		h[1] = 3 << 6
+	}
+	if z.dict != nil {
+		h[1] |= 1 << 5
+	}
+	h[1] += uint8(31 - (uint16(h[0])<<8+uint16(h[1]))%31)
+	if z.dict != nil {
+		h = binary.BigEndian.AppendUint32(h, adler32.Checksum(z.dict))
+	}
+	_, err := z.dst.Write(h)
+	return err
+}
+
+func (z *fullFlushWriter) Write(v []byte) (int, error) {
+	if !z.wroteHeader {
+		if err := z.writeHeader(); err != nil {
+			return 0, err
+		}
+	}
+	_, _ = z.adler.Write(v)
+	return z.writer.Write(v)
+}
+
+// Flush sync flush and reset the compressor, decoding with flate can start at the offset after it.
+func (z *fullFlushWriter) Flush() error {
+	if !z.wroteHeader {
+		if err := z.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if err := z.writer.Flush(); err != nil {
+		return err
+	}
+	if z.dict == nil {
+		z.writer.Reset(z.dst)
+		return nil
+	}
+
+	// Reset primes the dictionary again, which the decoder doesn't have at this point of the stream.
+	if z.plain == nil {
+		var err error
+		if z.plain, err = flate.NewWriter(z.dst, z.level); err != nil {
+			return err
+		}
+	} else {
+		z.plain.Reset(z.dst)
+	}
+	z.writer = z.plain
+	return nil
+}
+
+func (z *fullFlushWriter) Reset(w io.Writer) {
+	z.dst = w
+	z.writer = z.start
+	z.writer.Reset(w)
+	z.adler.Reset()
+	z.wroteHeader = false
+}
+
+func (z *fullFlushWriter) Close() error {
+	if !z.wroteHeader {
+		if err := z.writeHeader(); err != nil {
+			return err
+		}
+	}
+	if err := z.writer.Close(); err != nil {
+		return err
+	}
+	_, err := z.dst.Write(binary.BigEndian.AppendUint32(nil, z.adler.Sum32()))
+	return err
+}
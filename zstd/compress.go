@@ -0,0 +1,323 @@
+package zstd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/bits"
+	"runtime"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+)
+
+// levels valid compression levels.
+var levels = []compress.Level{
+	compress.DefaultCompression,
+	compress.BestSpeed, 2, 3, 4, 5, 6, 7, 8,
+	compress.BestCompression,
+}
+
+type zstdAlgorithm struct {
+	compress.Options
+
+	level       compress.Level
+	verify      bool
+	concurrency int
+	window      int
+	noChecksum  bool
+	maxMemory   int64
+	dict        []byte
+}
+
+type zstdEncoder struct {
+	writer *zstd.Encoder
+}
+
+type zstdDecoder struct {
+	reader    *zstd.Decoder
+	verify    bool
+	maxMemory int64
+}
+
+func (a *zstdAlgorithm) NewAlgorithm() compress.Algorithm {
+	return &zstdAlgorithm{level: compress.DefaultCompression}
+}
+
+func (a *zstdAlgorithm) Clone() compress.Algorithm {
+	c := *a
+	c.dict = append([]byte(nil), a.dict...)
+	return &c
+}
+
+func (a *zstdAlgorithm) Name() string {
+	return "zstd"
+}
+
+func (a *zstdAlgorithm) Ext() string {
+	return "zst"
+}
+
+func (a *zstdAlgorithm) String() string {
+	return fmt.Sprintf("zstd(level=%d, window=%d, concurrency=%d, dict=%d)", a.level, a.window, a.concurrency, len(a.dict))
+}
+
+func (a *zstdAlgorithm) Capabilities() compress.Capability {
+	return compress.CapLevel | compress.CapDictionary | compress.CapConcurrency | compress.CapFlush | compress.CapChecksum | compress.CapMultistream
+}
+
+func (a *zstdAlgorithm) ValidLevels() []compress.Level {
+	return levels
+}
+
+// DefaultLevel the zstd CLI level 3.
+func (a *zstdAlgorithm) DefaultLevel() compress.Level {
+	return 3
+}
+
+func (a *zstdAlgorithm) SetLevel(level compress.Level) error {
+	if !compress.ValidLevel(a, level) {
+		return errors.Wrapf(compress.ErrInvalidLevel, "algorithm zstd level %d", level)
+	}
+	a.level = level
+	return nil
+}
+
+func (a *zstdAlgorithm) SetEndian(endian compress.Endian) error {
+	return errors.Wrap(compress.ErrEndianUnsupported, "algorithm zstd")
+}
+
+func (a *zstdAlgorithm) SetLitWidth(width int) error {
+	return errors.Wrap(compress.ErrLitWidthUnsupported, "algorithm zstd")
+}
+
+// SetWindow between zstd.MinWindowSize and zstd.MaxWindowSize, the decoder rejects streams using a larger window.
+func (a *zstdAlgorithm) SetWindow(bits int) error {
+	if bits < 10 || bits > 29 {
+		return errors.Wrapf(compress.ErrInvalidWindowSize, "algorithm zstd window %d must be between 10 and 29", bits)
+	}
+	a.window = bits
+	return nil
+}
+
+func (a *zstdAlgorithm) SetName(name string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zstd")
+}
+
+func (a *zstdAlgorithm) SetComment(comment string) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zstd")
+}
+
+func (a *zstdAlgorithm) SetModTime(modTime time.Time) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zstd")
+}
+
+func (a *zstdAlgorithm) SetOS(os byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm zstd")
+}
+
+func (a *zstdAlgorithm) SetExtra(extra []byte) error {
+	return errors.Wrap(compress.ErrHeaderFieldUnsupported, "algorithm zstd")
+}
+
+// SetDeterministic is a no-op, the output of zstd is always the same for the same input.
+func (a *zstdAlgorithm) SetDeterministic(deterministic bool) error {
+	return nil
+}
+
+// SetDictionary either trained by "zstd --train" or raw content used as the initial history.
+func (a *zstdAlgorithm) SetDictionary(dict []byte) error {
+	a.dict = dict
+	return nil
+}
+
+// dictMagic at the start of a trained dictionary.
+var dictMagic = []byte{0x37, 0xa4, 0x30, 0xec}
+
+// trained dictionary, as opposed to raw content.
+func (a *zstdAlgorithm) trained() bool {
+	return bytes.HasPrefix(a.dict, dictMagic)
+}
+
+// encoderLevel maps a generic compression level onto the zstd speed presets.
+func encoderLevel(level compress.Level) zstd.EncoderLevel {
+	switch level {
+	case compress.NoCompression, compress.DefaultCompression:
+		return zstd.SpeedDefault
+	case compress.BestSpeed:
+		return zstd.SpeedFastest
+	case compress.BestCompression:
+		return zstd.SpeedBestCompression
+	}
+	return zstd.EncoderLevelFromZstd(int(level))
+}
+
+func (a *zstdAlgorithm) SetChecksumVerify(verify bool) error {
+	a.verify = verify
+	return nil
+}
+
+func (a *zstdAlgorithm) SetNoChecksum(noChecksum bool) error {
+	a.noChecksum = noChecksum
+	return nil
+}
+
+func (a *zstdAlgorithm) SetTrustSize(trust bool) error {
+	return errors.Wrap(compress.ErrUnsupportedOption, "algorithm zstd")
+}
+
+func (a *zstdAlgorithm) SetFlushMode(mode compress.FlushMode) error {
+	return errors.Wrap(compress.ErrFlushModeUnsupported, "algorithm zstd")
+}
+
+func (a *zstdAlgorithm) SetConcurrency(n int) error {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	a.concurrency = n
+	return nil
+}
+
+// SetBlockSize zstd blocks are at most 128KB, the frame is bounded by the window so this sets the window size.
+func (a *zstdAlgorithm) SetBlockSize(n int) error {
+	if n <= 0 || n&(n-1) != 0 {
+		return errors.Wrapf(compress.ErrInvalidBlockSize, "algorithm zstd block size %d must be a power of two", n)
+	}
+	if err := a.SetWindow(bits.Len(uint(n)) - 1); err != nil {
+		return errors.Wrapf(compress.ErrInvalidBlockSize, "algorithm zstd block size %d must be between 1KB and 512MB", n)
+	}
+	return nil
+}
+
+// SetMaxMemory decoder rejects streams with a window larger than n.
+func (a *zstdAlgorithm) SetMaxMemory(n int64) error {
+	if n < zstd.MinWindowSize {
+		return errors.Wrapf(compress.ErrInvalidMemoryLimit, "algorithm zstd max memory %d must be at least %d", n, zstd.MinWindowSize)
+	}
+	a.maxMemory = n
+	return nil
+}
+
+// Validate there is a checksum to verify.
+func (a *zstdAlgorithm) Validate() error {
+	if a.verify && a.noChecksum {
+		return errors.Wrap(compress.ErrConflictingOptions, "algorithm zstd checksum verify without checksum")
+	}
+	return nil
+}
+
+func (a *zstdAlgorithm) NewEncoder(w io.Writer) (compress.Encoder, error) {
+	opts := []zstd.EOption{zstd.WithEncoderLevel(encoderLevel(a.level))}
+	if a.concurrency > 0 {
+		opts = append(opts, zstd.WithEncoderConcurrency(a.concurrency))
+	}
+	if a.window > 0 {
+		opts = append(opts, zstd.WithWindowSize(1<<uint(a.window)))
+	}
+	if a.noChecksum {
+		opts = append(opts, zstd.WithEncoderCRC(false))
+	}
+	switch {
+	case a.trained():
+		opts = append(opts, zstd.WithEncoderDict(a.dict))
+	case len(a.dict) > 0:
+		// Without an ID the frame doesn't reference the dictionary, the decoder uses the one with ID 0.
+		opts = append(opts, zstd.WithEncoderDictRaw(0, a.dict))
+	}
+
+	e := &zstdEncoder{}
+	var err error
+	if e.writer, err = zstd.NewWriter(w, opts...); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (a *zstdAlgorithm) Encode(v []byte) ([]byte, error) {
+	return compress.Encode(a, v)
+}
+
+func (e *zstdEncoder) Write(v []byte) (int, error) {
+	return e.writer.Write(v)
+}
+
+func (e *zstdEncoder) ReadFrom(r io.Reader) (int64, error) {
+	return e.writer.ReadFrom(r)
+}
+
+func (e *zstdEncoder) Reset(w io.Writer) error {
+	e.writer.Reset(w)
+	return nil
+}
+
+func (e *zstdEncoder) Flush() error {
+	return e.writer.Flush()
+}
+
+func (e *zstdEncoder) Close() error {
+	return e.writer.Close()
+}
+
+func (a *zstdAlgorithm) NewDecoder(r io.Reader) (compress.Decoder, error) {
+	var opts []zstd.DOption
+	switch {
+	case a.maxMemory > 0 && (a.window == 0 || a.maxMemory < 1<<uint(a.window)):
+		opts = append(opts, zstd.WithDecoderMaxWindow(uint64(a.maxMemory)))
+	case a.window > 0:
+		opts = append(opts, zstd.WithDecoderMaxWindow(1<<uint(a.window)))
+	}
+	switch {
+	case a.trained():
+		opts = append(opts, zstd.WithDecoderDicts(a.dict))
+	case len(a.dict) > 0:
+		opts = append(opts, zstd.WithDecoderDictRaw(0, a.dict))
+	}
+
+	d := &zstdDecoder{verify: a.verify, maxMemory: a.maxMemory}
+	var err error
+	if d.reader, err = zstd.NewReader(r, opts...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (a *zstdAlgorithm) Decode(v []byte) ([]byte, error) {
+	return compress.Decode(a, v)
+}
+
+func (d *zstdDecoder) Read(v []byte) (int, error) {
+	n, err := d.reader.Read(v)
+	return n, d.wrap(err)
+}
+
+func (d *zstdDecoder) WriteTo(w io.Writer) (int64, error) {
+	n, err := d.reader.WriteTo(w)
+	return n, d.wrap(err)
+}
+
+// wrap errors from zstd with the errors for the options set.
+func (d *zstdDecoder) wrap(err error) error {
+	switch {
+	case d.verify && err == zstd.ErrCRCMismatch:
+		return errors.Wrap(compress.ErrChecksumMismatch, "algorithm zstd")
+	case d.maxMemory > 0 && err == zstd.ErrWindowSizeExceeded:
+		return errors.Wrapf(compress.ErrMemoryLimit, "algorithm zstd window exceeds max memory %d", d.maxMemory)
+	}
+	return err
+}
+
+func (d *zstdDecoder) Reset(r io.Reader) error {
+	return d.reader.Reset(r)
+}
+
+func (d *zstdDecoder) Close() error {
+	d.reader.Close()
+	return nil
+}
+
+func init() {
+	compress.Register("zstd", &zstdAlgorithm{})
+}
@@ -0,0 +1,246 @@
+package zstd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mickep76/compress"
+	_ "github.com/mickep76/compress/gzip"
+)
+
+func TestSetLevel(t *testing.T) {
+	if _, err := compress.NewAlgorithm("zstd", compress.WithLevel(compress.HuffmanOnly)); err == nil {
+		t.Error("huffman only should not be a supported level")
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&buf, "line %d: the quick brown fox jumps over the lazy dog %d\n", i, i%7)
+	}
+
+	sizes := map[compress.Level]int{}
+	for _, level := range []compress.Level{compress.BestSpeed, compress.BestCompression} {
+		a, err := compress.NewAlgorithm("zstd", compress.WithLevel(level))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[level] = len(encoded)
+	}
+
+	if sizes[compress.BestCompression] >= sizes[compress.BestSpeed] {
+		t.Errorf("best compression (%d bytes) should be smaller than best speed (%d bytes)", sizes[compress.BestCompression], sizes[compress.BestSpeed])
+	}
+}
+
+func TestDetectByExt(t *testing.T) {
+	if name, err := compress.DetectByExt("abc.tar.ZST"); err != nil {
+		t.Error(err)
+	} else if name != "zstd" {
+		t.Errorf("expected zstd got %s", name)
+	}
+}
+
+func TestValidLevels(t *testing.T) {
+	for _, level := range []compress.Level{compress.DefaultCompression, compress.BestSpeed, compress.BestCompression} {
+		if _, err := compress.NewAlgorithm("zstd", compress.WithLevel(level)); err != nil {
+			t.Errorf("level %d should be valid: %v", level, err)
+		}
+	}
+
+	for _, level := range []compress.Level{compress.HuffmanOnly, compress.NoCompression, 10} {
+		if _, err := compress.NewAlgorithm("zstd", compress.WithLevel(level)); errors.Cause(err) != compress.ErrInvalidLevel {
+			t.Errorf("level %d expected %v got %v", level, compress.ErrInvalidLevel, err)
+		}
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	var buf bytes.Buffer
+	for buf.Len() < 4<<20 {
+		fmt.Fprintf(&buf, "line %d: the quick brown fox jumps over the lazy dog\n", buf.Len())
+	}
+
+	for _, n := range []int{0, 4} {
+		a, err := compress.NewAlgorithm("zstd", compress.WithConcurrency(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(buf.Bytes(), got) {
+			t.Errorf("concurrency %d decode doesn't match expected value", n)
+		}
+	}
+}
+
+func TestWindowSize(t *testing.T) {
+	for _, n := range []int{1000, 1 << 9, 1 << 30} {
+		if _, err := compress.NewAlgorithm("zstd", compress.WithWindowSize(n)); errors.Cause(err) != compress.ErrInvalidWindowSize {
+			t.Errorf("window size %d expected %v got %v", n, compress.ErrInvalidWindowSize, err)
+		}
+	}
+
+	a, err := compress.NewAlgorithm("zstd", compress.WithWindowSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 100000)
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := a.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+}
+
+func TestMaxMemory(t *testing.T) {
+	if _, err := compress.NewAlgorithm("zstd", compress.WithMaxMemory(512)); errors.Cause(err) != compress.ErrInvalidMemoryLimit {
+		t.Errorf("expected %v got %v", compress.ErrInvalidMemoryLimit, err)
+	}
+
+	a, err := compress.NewAlgorithm("zstd", compress.WithWindowSize(8<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 100000)
+	encoded, err := a.Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := compress.NewAlgorithm("zstd", compress.WithMaxMemory(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Decode(encoded); errors.Cause(err) != compress.ErrMemoryLimit {
+		t.Errorf("expected %v got %v", compress.ErrMemoryLimit, err)
+	}
+
+	c, err := compress.NewAlgorithm("zstd", compress.WithMaxMemory(16<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Decode(encoded); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("decode doesn't match expected value")
+	}
+}
+
+func TestDictionaryFile(t *testing.T) {
+	exp := []byte(`{"id":42,"type":"event","source":"sensor-2","status":"ok","value":294}`)
+	plain, err := compress.MustNewAlgorithm("zstd").Encode(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// testdata/trained.dict is built from similar messages, testdata/raw.dict is a message.
+	for _, path := range []string{"testdata/trained.dict", "testdata/raw.dict"} {
+		a, err := compress.NewAlgorithm("zstd", compress.WithDictionaryFile(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(encoded) >= len(plain) {
+			t.Errorf("%s: expected smaller than %d bytes without the dictionary got %d", path, len(plain), len(encoded))
+		}
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Errorf("%s: %v", path, err)
+		} else if !bytes.Equal(exp, got) {
+			t.Errorf("%s: decode doesn't match expected value", path)
+		}
+	}
+
+	if _, err := compress.NewAlgorithm("zstd", compress.WithDictionaryFile("testdata/missing.dict")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error got %v", err)
+	}
+
+	if _, err := compress.NewAlgorithm("gzip", compress.WithDictionaryFile("testdata/raw.dict")); errors.Cause(err) != compress.ErrDictionaryUnsupported {
+		t.Errorf("expected %v got %v", compress.ErrDictionaryUnsupported, err)
+	}
+}
+
+func TestDefaultLevel(t *testing.T) {
+	a, err := compress.NewAlgorithm("zstd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.DefaultLevel() != 3 {
+		t.Errorf("expected 3 got %d", a.DefaultLevel())
+	}
+
+	// The default level produces the same output as not setting a level.
+	v := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+	exp, err := a.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := compress.MustNewAlgorithm("zstd", compress.WithLevel(a.DefaultLevel())).Encode(v); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(exp, got) {
+		t.Error("default level output doesn't match the unset level")
+	}
+}
+
+func TestNoChecksum(t *testing.T) {
+	exp := bytes.Repeat([]byte("abc123\ndef456\n"), 1000)
+
+	sizes := map[bool]int{}
+	for _, noChecksum := range []bool{false, true} {
+		a, err := compress.NewAlgorithm("zstd", compress.WithNoChecksum(noChecksum))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := a.Encode(exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[noChecksum] = len(encoded)
+
+		if got, err := a.Decode(encoded); err != nil {
+			t.Error(err)
+		} else if !bytes.Equal(exp, got) {
+			t.Error("decode doesn't match expected value")
+		}
+	}
+
+	// The checksum is the lower 4 bytes of XXH64.
+	if sizes[true] != sizes[false]-4 {
+		t.Errorf("without checksum expected %d bytes got %d", sizes[false]-4, sizes[true])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if _, err := compress.NewAlgorithm("zstd", compress.WithChecksumVerify(true), compress.WithNoChecksum(true)); errors.Cause(err) != compress.ErrConflictingOptions {
+		t.Errorf("expected %v got %v", compress.ErrConflictingOptions, err)
+	}
+}